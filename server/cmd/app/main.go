@@ -1,7 +1,16 @@
 package main
 
-import "github.com/morf1lo/blog-app/internal/app"
+import (
+	"os"
+
+	"github.com/morf1lo/blog-app/internal/app"
+)
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		app.Migrate(os.Args[2:])
+		return
+	}
+
 	app.Run()
 }
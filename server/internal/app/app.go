@@ -1,28 +1,60 @@
 package app
 
 import (
+	"context"
+	"errors"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
 
 	"github.com/gin-gonic/gin"
 
 	"github.com/morf1lo/blog-app/internal/config"
 	"github.com/morf1lo/blog-app/internal/db"
 	"github.com/morf1lo/blog-app/internal/handler"
+	"github.com/morf1lo/blog-app/internal/migrate"
+	"github.com/morf1lo/blog-app/internal/repository"
 	"github.com/morf1lo/blog-app/internal/service"
 )
 
 func Run() {
-	if err := config.Init(); err != nil {
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	db, err := db.Connect(cfg.DB)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	migrator, err := migrate.NewMigrator(db, cfg.DB.Driver)
+	if err != nil {
 		log.Fatal(err)
 	}
 
-	db, err := db.Connect()
+	applied, err := migrator.Up()
 	if err != nil {
 		log.Fatal(err)
 	}
+	if applied > 0 {
+		log.Printf("applied %d migration(s)", applied)
+	}
 
-	services := service.NewService(db)
-	handlers := handler.NewHandler(services)
+	repos := repository.NewRepository(db)
+	services := service.NewService(repos, cfg)
+	handlers := handler.NewHandler(services, cfg)
+
+	indexed, err := services.Search.IndexMissing()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if indexed > 0 {
+		log.Printf("indexed %d post(s) and comment(s) for search", indexed)
+	}
 
 	router := gin.New()
 
@@ -32,5 +64,51 @@ func Run() {
 
 	handlers.SetupRoutes(router)
 
-	router.Run(":8080")
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+
+	var workers sync.WaitGroup
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		runPublisher(workerCtx, services.Post, cfg.Posts.PublishInterval)
+	}()
+
+	srv := NewServer(cfg.Server, router)
+
+	serverErr := make(chan error, 1)
+	go func() {
+		log.Printf("listening on %s", cfg.Server.Addr)
+		if err := srv.Run(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+		}
+		close(serverErr)
+	}()
+
+	select {
+	case err := <-serverErr:
+		if err != nil {
+			log.Printf("server error: %s", err)
+		}
+	case <-ctx.Done():
+		log.Print("shutting down")
+	}
+	stop()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("server shutdown: %s", err)
+	}
+
+	stopWorkers()
+	workers.Wait()
+
+	if err := db.Close(); err != nil {
+		log.Printf("database close: %s", err)
+	}
 }
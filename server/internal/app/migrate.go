@@ -0,0 +1,66 @@
+package app
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/morf1lo/blog-app/internal/config"
+	"github.com/morf1lo/blog-app/internal/db"
+	"github.com/morf1lo/blog-app/internal/migrate"
+)
+
+const migrateUsage = "usage: app migrate up|down|status"
+
+func Migrate(args []string) {
+	if len(args) != 1 {
+		log.Fatal(migrateUsage)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	db, err := db.Connect(cfg.DB)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	migrator, err := migrate.NewMigrator(db, cfg.DB.Driver)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up()
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("applied %d migration(s)\n", applied)
+	case "down":
+		migration, err := migrator.Down()
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("rolled back %04d_%s\n", migration.Version, migration.Name)
+	case "status":
+		statuses, err := migrator.Status()
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, status := range statuses {
+			state := "pending"
+			if status.Applied {
+				state = "applied at " + status.AppliedAt
+			}
+			if status.Dirty {
+				state = "dirty, failed part way"
+			}
+			fmt.Printf("%04d_%s\t%s\n", status.Version, status.Name, state)
+		}
+	default:
+		log.Fatal(migrateUsage)
+	}
+}
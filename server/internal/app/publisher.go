@@ -0,0 +1,36 @@
+package app
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/morf1lo/blog-app/internal/service"
+)
+
+// runPublisher flips due scheduled posts to published every interval until ctx is cancelled.
+func runPublisher(ctx context.Context, posts service.Post, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		publish(posts)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func publish(posts service.Post) {
+	published, err := posts.PublishScheduledPosts()
+	if err != nil {
+		log.Printf("publish scheduled posts: %s", err)
+		return
+	}
+	if published > 0 {
+		log.Printf("published %d scheduled post(s)", published)
+	}
+}
@@ -0,0 +1,32 @@
+package app
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/morf1lo/blog-app/internal/config"
+)
+
+type Server struct {
+	httpServer *http.Server
+}
+
+func NewServer(cfg config.ServerConfig, handler http.Handler) *Server {
+	return &Server{
+		httpServer: &http.Server{
+			Addr: cfg.Addr,
+			Handler: handler,
+			ReadTimeout: cfg.ReadTimeout,
+			WriteTimeout: cfg.WriteTimeout,
+			IdleTimeout: cfg.IdleTimeout,
+		},
+	}
+}
+
+func (s *Server) Run() error {
+	return s.httpServer.ListenAndServe()
+}
+
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.httpServer.Shutdown(ctx)
+}
@@ -1,10 +1,342 @@
 package config
 
-import "github.com/joho/godotenv"
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
 
-func Init() error {
-	if err := godotenv.Load(".env"); err != nil {
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+const defaultConfigFile = "config.yaml"
+
+type Config struct {
+	Server    ServerConfig   `yaml:"server"`
+	ClientURL string         `yaml:"client_url"`
+	Auth      AuthConfig     `yaml:"auth"`
+	DB        DBConfig       `yaml:"db"`
+	Mail      MailConfig     `yaml:"mail"`
+	Posts     PostsConfig    `yaml:"posts"`
+	Comments  CommentsConfig `yaml:"comments"`
+	Reactions []Reaction     `yaml:"reactions"`
+}
+
+type ServerConfig struct {
+	URL             string        `yaml:"url"`
+	Addr            string        `yaml:"addr"`
+	ReadTimeout     time.Duration `yaml:"read_timeout"`
+	WriteTimeout    time.Duration `yaml:"write_timeout"`
+	IdleTimeout     time.Duration `yaml:"idle_timeout"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+}
+
+type AuthConfig struct {
+	Secret          string        `yaml:"secret"`
+	AccessTokenTTL  time.Duration `yaml:"access_token_ttl"`
+	RefreshTokenTTL time.Duration `yaml:"refresh_token_ttl"`
+	Cookie          CookieConfig  `yaml:"cookie"`
+}
+
+// CookieConfig controls the cookies the tokens are sent in. An empty domain
+// makes them host-only.
+type CookieConfig struct {
+	Domain   string `yaml:"domain"`
+	SameSite string `yaml:"same_site"`
+	Secure   bool   `yaml:"secure"`
+}
+
+func (c CookieConfig) SameSiteMode() http.SameSite {
+	switch c.SameSite {
+	case "strict":
+		return http.SameSiteStrictMode
+	case "none":
+		return http.SameSiteNoneMode
+	default:
+		return http.SameSiteLaxMode
+	}
+}
+
+type DBConfig struct {
+	Driver   string `yaml:"driver"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	Host     string `yaml:"host"`
+	Name     string `yaml:"name"`
+	Path     string `yaml:"path"`
+}
+
+type MailConfig struct {
+	From     string `yaml:"from"`
+	Password string `yaml:"password"`
+	Host     string `yaml:"host"`
+	Port     string `yaml:"port"`
+}
+
+type PostsConfig struct {
+	PublishInterval time.Duration `yaml:"publish_interval"`
+}
+
+type CommentsConfig struct {
+	EditWindow time.Duration `yaml:"edit_window"`
+}
+
+// Reaction is one of the emoji reactions users can leave on posts and comments.
+type Reaction struct {
+	Name  string `yaml:"name"`
+	Emoji string `yaml:"emoji"`
+}
+
+func defaults() *Config {
+	return &Config{
+		Server: ServerConfig{
+			URL: "http://localhost:8080",
+			Addr: ":8080",
+			ReadTimeout: 10 * time.Second,
+			WriteTimeout: 30 * time.Second,
+			IdleTimeout: 60 * time.Second,
+			ShutdownTimeout: 15 * time.Second,
+		},
+		ClientURL: "http://localhost:3000",
+		Auth: AuthConfig{
+			AccessTokenTTL: 15 * time.Minute,
+			RefreshTokenTTL: 30 * 24 * time.Hour,
+			Cookie: CookieConfig{
+				SameSite: "lax",
+				Secure: true,
+			},
+		},
+		DB: DBConfig{
+			Driver: "mysql",
+			Host: "localhost",
+			Path: "blog.db",
+		},
+		Mail: MailConfig{
+			Port: "587",
+		},
+		Posts: PostsConfig{
+			PublishInterval: 30 * time.Second,
+		},
+		Comments: CommentsConfig{
+			EditWindow: 15 * time.Minute,
+		},
+		Reactions: []Reaction{
+			{Name: "like", Emoji: "👍"},
+			{Name: "love", Emoji: "❤️"},
+			{Name: "laugh", Emoji: "😂"},
+			{Name: "wow", Emoji: "😮"},
+			{Name: "sad", Emoji: "😢"},
+			{Name: "celebrate", Emoji: "🎉"},
+		},
+	}
+}
+
+// Load builds the configuration from defaults, an optional YAML file
+// (CONFIG_FILE or ./config.yaml) and environment variables, in increasing
+// order of precedence. Variables from .env are loaded into the environment first.
+func Load() (*Config, error) {
+	if err := godotenv.Load(".env"); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	cfg := defaults()
+
+	if err := cfg.loadFile(); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.loadEnv(); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+func (c *Config) loadFile() error {
+	path, explicit := os.LookupEnv("CONFIG_FILE")
+	if !explicit {
+		path = defaultConfigFile
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if !explicit && errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
 		return err
 	}
+
+	if err := yaml.Unmarshal(content, c); err != nil {
+		return fmt.Errorf("config file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+func (c *Config) envBindings() map[string]*string {
+	return map[string]*string{
+		"SERVER_URL": &c.Server.URL,
+		"HTTP_ADDR": &c.Server.Addr,
+		"CLIENT_URL": &c.ClientURL,
+		"SECRET": &c.Auth.Secret,
+		"COOKIE_DOMAIN": &c.Auth.Cookie.Domain,
+		"COOKIE_SAMESITE": &c.Auth.Cookie.SameSite,
+		"DB_DRIVER": &c.DB.Driver,
+		"DB_USERNAME": &c.DB.Username,
+		"DB_PASSWORD": &c.DB.Password,
+		"DB_HOST": &c.DB.Host,
+		"DATABASE": &c.DB.Name,
+		"DB_PATH": &c.DB.Path,
+		"EMAIL": &c.Mail.From,
+		"EMAIL_PASSWORD": &c.Mail.Password,
+		"SMTP_HOST": &c.Mail.Host,
+		"SMTP_PORT": &c.Mail.Port,
+	}
+}
+
+func (c *Config) durationEnvBindings() map[string]*time.Duration {
+	return map[string]*time.Duration{
+		"HTTP_READ_TIMEOUT": &c.Server.ReadTimeout,
+		"HTTP_WRITE_TIMEOUT": &c.Server.WriteTimeout,
+		"HTTP_IDLE_TIMEOUT": &c.Server.IdleTimeout,
+		"HTTP_SHUTDOWN_TIMEOUT": &c.Server.ShutdownTimeout,
+		"POST_PUBLISH_INTERVAL": &c.Posts.PublishInterval,
+		"COMMENT_EDIT_WINDOW": &c.Comments.EditWindow,
+		"ACCESS_TOKEN_TTL": &c.Auth.AccessTokenTTL,
+		"REFRESH_TOKEN_TTL": &c.Auth.RefreshTokenTTL,
+	}
+}
+
+func (c *Config) loadEnv() error {
+	for name, field := range c.envBindings() {
+		if value, ok := os.LookupEnv(name); ok {
+			*field = value
+		}
+	}
+
+	for name, field := range c.durationEnvBindings() {
+		if value, ok := os.LookupEnv(name); ok {
+			duration, err := time.ParseDuration(value)
+			if err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+			*field = duration
+		}
+	}
+
+	if value, ok := os.LookupEnv("COOKIE_SECURE"); ok {
+		secure, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("COOKIE_SECURE: %w", err)
+		}
+		c.Auth.Cookie.Secure = secure
+	}
+
+	if value, ok := os.LookupEnv("REACTIONS"); ok {
+		reactions, err := parseReactions(value)
+		if err != nil {
+			return fmt.Errorf("REACTIONS: %w", err)
+		}
+		c.Reactions = reactions
+	}
+
+	return nil
+}
+
+// parseReactions reads a comma-separated list of name:emoji pairs.
+func parseReactions(value string) ([]Reaction, error) {
+	var reactions []Reaction
+	for _, pair := range strings.Split(value, ",") {
+		name, emoji, ok := strings.Cut(strings.TrimSpace(pair), ":")
+		if !ok {
+			return nil, fmt.Errorf("%q is not a name:emoji pair", pair)
+		}
+		reactions = append(reactions, Reaction{Name: strings.TrimSpace(name), Emoji: strings.TrimSpace(emoji)})
+	}
+	return reactions, nil
+}
+
+func (c *Config) Validate() error {
+	var problems []string
+
+	if strings.TrimSpace(c.Auth.Secret) == "" {
+		problems = append(problems, "SECRET is required")
+	}
+	if c.Auth.AccessTokenTTL <= 0 {
+		problems = append(problems, "ACCESS_TOKEN_TTL must be positive")
+	}
+	if c.Auth.RefreshTokenTTL < c.Auth.AccessTokenTTL {
+		problems = append(problems, "REFRESH_TOKEN_TTL must not be shorter than ACCESS_TOKEN_TTL")
+	}
+	switch c.Auth.Cookie.SameSite {
+	case "lax", "strict":
+	case "none":
+		// Browsers reject SameSite=None cookies that are not secure
+		if !c.Auth.Cookie.Secure {
+			problems = append(problems, "COOKIE_SAMESITE=none requires COOKIE_SECURE")
+		}
+	default:
+		problems = append(problems, fmt.Sprintf("COOKIE_SAMESITE %q must be lax, strict or none", c.Auth.Cookie.SameSite))
+	}
+	if c.Server.URL == "" {
+		problems = append(problems, "SERVER_URL is required")
+	}
+	if c.Server.Addr == "" {
+		problems = append(problems, "HTTP_ADDR is required")
+	}
+	if c.Server.ShutdownTimeout <= 0 {
+		problems = append(problems, "HTTP_SHUTDOWN_TIMEOUT must be positive")
+	}
+	if c.Posts.PublishInterval <= 0 {
+		problems = append(problems, "POST_PUBLISH_INTERVAL must be positive")
+	}
+	if c.Comments.EditWindow < 0 {
+		problems = append(problems, "COMMENT_EDIT_WINDOW must not be negative")
+	}
+	if len(c.Reactions) == 0 {
+		problems = append(problems, "REACTIONS must not be empty")
+	}
+	reactionNames := make(map[string]bool, len(c.Reactions))
+	for _, reaction := range c.Reactions {
+		if reaction.Name == "" || len(reaction.Name) > 32 || reaction.Emoji == "" {
+			problems = append(problems, fmt.Sprintf("reaction %q needs a name of up to 32 characters and an emoji", reaction.Name))
+		}
+		if reactionNames[reaction.Name] {
+			problems = append(problems, fmt.Sprintf("reaction %q is defined twice", reaction.Name))
+		}
+		reactionNames[reaction.Name] = true
+	}
+	if c.ClientURL == "" {
+		problems = append(problems, "CLIENT_URL is required")
+	}
+
+	switch c.DB.Driver {
+	case "mysql":
+		if c.DB.Host == "" {
+			problems = append(problems, "DB_HOST is required for the mysql driver")
+		}
+		if c.DB.Name == "" {
+			problems = append(problems, "DATABASE is required for the mysql driver")
+		}
+	case "sqlite":
+		if c.DB.Path == "" {
+			problems = append(problems, "DB_PATH is required for the sqlite driver")
+		}
+	default:
+		problems = append(problems, fmt.Sprintf("DB_DRIVER %q is not supported", c.DB.Driver))
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid configuration: %s", strings.Join(problems, "; "))
+	}
+
 	return nil
 }
@@ -0,0 +1,218 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// clearEnv unsets every variable Load reads, so that the environment the tests
+// run in does not leak into them. The variables are restored afterwards.
+func clearEnv(t *testing.T) {
+	t.Helper()
+
+	c := &Config{}
+	names := []string{"CONFIG_FILE", "COOKIE_SECURE", "REACTIONS"}
+	for name := range c.envBindings() {
+		names = append(names, name)
+	}
+	for name := range c.durationEnvBindings() {
+		names = append(names, name)
+	}
+
+	for _, name := range names {
+		t.Setenv(name, "")
+		os.Unsetenv(name)
+	}
+}
+
+func writeConfigFile(t *testing.T, content string) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("CONFIG_FILE", path)
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	clearEnv(t)
+	writeConfigFile(t, `
+client_url: https://file.example.com
+server:
+  addr: ":9000"
+  read_timeout: 20s
+  write_timeout: 40s
+auth:
+  secret: file secret
+  cookie:
+    same_site: strict
+db:
+  driver: sqlite
+  path: file.db
+reactions:
+  - name: up
+    emoji: "⬆️"
+`)
+	t.Setenv("HTTP_ADDR", ":9100")
+	t.Setenv("HTTP_WRITE_TIMEOUT", "50s")
+	t.Setenv("SECRET", "env secret")
+	t.Setenv("COOKIE_SECURE", "false")
+	t.Setenv("REACTIONS", "up: ⬆️, down :⬇️")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name     string
+		got      interface{}
+		expected interface{}
+	}{
+		{"default", cfg.Server.URL, "http://localhost:8080"},
+		{"default duration", cfg.Server.IdleTimeout, 60 * time.Second},
+		{"file", cfg.ClientURL, "https://file.example.com"},
+		{"file duration", cfg.Server.ReadTimeout, 20 * time.Second},
+		{"file nested", cfg.Auth.Cookie.SameSite, "strict"},
+		{"file over default", cfg.DB.Driver, "sqlite"},
+		{"env over file", cfg.Server.Addr, ":9100"},
+		{"env duration over file", cfg.Server.WriteTimeout, 50 * time.Second},
+		{"env secret over file", cfg.Auth.Secret, "env secret"},
+		{"env bool over default", cfg.Auth.Cookie.Secure, false},
+		{"env reactions count", len(cfg.Reactions), 2},
+		{"env reaction trimmed", cfg.Reactions[1], Reaction{Name: "down", Emoji: "⬇️"}},
+	}
+
+	for _, tc := range cases {
+		if tc.got != tc.expected {
+			t.Errorf("%s: got %v, want %v", tc.name, tc.got, tc.expected)
+		}
+	}
+}
+
+func TestLoadWithoutFile(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("SECRET", "secret")
+	t.Setenv("DATABASE", "blog")
+
+	// No CONFIG_FILE and no ./config.yaml next to the test
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.DB.Driver != "mysql" || cfg.DB.Name != "blog" {
+		t.Errorf("db = %+v", cfg.DB)
+	}
+
+	t.Setenv("CONFIG_FILE", filepath.Join(t.TempDir(), "missing.yaml"))
+	if _, err := Load(); err == nil {
+		t.Error("a missing CONFIG_FILE was ignored")
+	}
+}
+
+func TestLoadRejectsBadValues(t *testing.T) {
+	cases := []struct {
+		name  string
+		value string
+	}{
+		{"HTTP_READ_TIMEOUT", "10"},
+		{"COMMENT_EDIT_WINDOW", "soon"},
+		{"ACCESS_TOKEN_TTL", "1h30"},
+		{"COOKIE_SECURE", "yes please"},
+		{"REACTIONS", "like:👍,love"},
+		{"REACTIONS", ""},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name+"="+tc.value, func(t *testing.T) {
+			clearEnv(t)
+			t.Setenv("SECRET", "secret")
+			t.Setenv("DATABASE", "blog")
+			t.Setenv(tc.name, tc.value)
+
+			_, err := Load()
+			if err == nil {
+				t.Fatal("Load accepted the value")
+			}
+			if !strings.Contains(err.Error(), tc.name) {
+				t.Errorf("error %q does not name %s", err, tc.name)
+			}
+		})
+	}
+
+	t.Run("file", func(t *testing.T) {
+		clearEnv(t)
+		writeConfigFile(t, "server:\n  read_timeout: [10s]\n")
+		if _, err := Load(); err == nil {
+			t.Fatal("Load accepted a malformed file")
+		}
+	})
+}
+
+func validConfig() *Config {
+	cfg := defaults()
+	cfg.Auth.Secret = "secret"
+	cfg.DB.Name = "blog"
+	return cfg
+}
+
+func TestValidate(t *testing.T) {
+	if err := validConfig().Validate(); err != nil {
+		t.Fatalf("valid config: %v", err)
+	}
+
+	cases := []struct {
+		name    string
+		change  func(c *Config)
+		problem string
+	}{
+		{"no secret", func(c *Config) { c.Auth.Secret = " " }, "SECRET is required"},
+		{"zero access token ttl", func(c *Config) { c.Auth.AccessTokenTTL = 0 }, "ACCESS_TOKEN_TTL must be positive"},
+		{"short refresh token ttl", func(c *Config) { c.Auth.RefreshTokenTTL = time.Minute }, "REFRESH_TOKEN_TTL must not be shorter"},
+		{"insecure samesite none", func(c *Config) { c.Auth.Cookie.SameSite, c.Auth.Cookie.Secure = "none", false }, "COOKIE_SAMESITE=none requires COOKIE_SECURE"},
+		{"unknown samesite", func(c *Config) { c.Auth.Cookie.SameSite = "Lax" }, `COOKIE_SAMESITE "Lax" must be`},
+		{"no server url", func(c *Config) { c.Server.URL = "" }, "SERVER_URL is required"},
+		{"no addr", func(c *Config) { c.Server.Addr = "" }, "HTTP_ADDR is required"},
+		{"zero shutdown timeout", func(c *Config) { c.Server.ShutdownTimeout = 0 }, "HTTP_SHUTDOWN_TIMEOUT must be positive"},
+		{"zero publish interval", func(c *Config) { c.Posts.PublishInterval = 0 }, "POST_PUBLISH_INTERVAL must be positive"},
+		{"negative edit window", func(c *Config) { c.Comments.EditWindow = -time.Second }, "COMMENT_EDIT_WINDOW must not be negative"},
+		{"no reactions", func(c *Config) { c.Reactions = nil }, "REACTIONS must not be empty"},
+		{"reaction without name", func(c *Config) { c.Reactions[0].Name = "" }, `reaction "" needs a name`},
+		{"reaction without emoji", func(c *Config) { c.Reactions[0].Emoji = "" }, `reaction "like" needs a name`},
+		{"long reaction name", func(c *Config) { c.Reactions[0].Name = strings.Repeat("a", 33) }, "needs a name of up to 32 characters"},
+		{"duplicate reaction", func(c *Config) { c.Reactions[1].Name = "like" }, `reaction "like" is defined twice`},
+		{"no client url", func(c *Config) { c.ClientURL = "" }, "CLIENT_URL is required"},
+		{"mysql without host", func(c *Config) { c.DB.Host = "" }, "DB_HOST is required for the mysql driver"},
+		{"mysql without database", func(c *Config) { c.DB.Name = "" }, "DATABASE is required for the mysql driver"},
+		{"sqlite without path", func(c *Config) { c.DB.Driver, c.DB.Path = "sqlite", "" }, "DB_PATH is required for the sqlite driver"},
+		{"unknown driver", func(c *Config) { c.DB.Driver = "postgres" }, `DB_DRIVER "postgres" is not supported`},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := validConfig()
+			tc.change(cfg)
+
+			err := cfg.Validate()
+			if err == nil {
+				t.Fatal("Validate accepted the config")
+			}
+			if !strings.Contains(err.Error(), tc.problem) {
+				t.Errorf("error %q does not contain %q", err, tc.problem)
+			}
+		})
+	}
+
+	// Every problem is reported at once
+	cfg := validConfig()
+	cfg.Auth.Secret = ""
+	cfg.DB.Driver = "postgres"
+	err := cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "SECRET") || !strings.Contains(err.Error(), "DB_DRIVER") {
+		t.Errorf("Validate = %v, want both problems", err)
+	}
+}
@@ -3,18 +3,40 @@ package db
 import (
 	"database/sql"
 	"fmt"
-	"os"
-	
+
 	_ "github.com/go-sql-driver/mysql"
+	_ "modernc.org/sqlite"
+
+	"github.com/morf1lo/blog-app/internal/config"
+)
+
+const (
+	DriverMySQL  = "mysql"
+	DriverSQLite = "sqlite"
 )
 
-func Connect() (*sql.DB, error) {
-	username := os.Getenv("DB_USERNAME")
-	password := os.Getenv("DB_PASSWORD")
-	host := os.Getenv("DB_HOST")
-	database := os.Getenv("DATABASE")
+func Connect(cfg config.DBConfig) (*sql.DB, error) {
+	switch cfg.Driver {
+	case DriverMySQL:
+		return connectMySQL(cfg)
+	case DriverSQLite:
+		return connectSQLite(cfg)
+	default:
+		return nil, fmt.Errorf("unsupported database driver: %s", cfg.Driver)
+	}
+}
+
+func connectMySQL(cfg config.DBConfig) (*sql.DB, error) {
+	db, err := sql.Open("mysql", fmt.Sprintf("%s:%s@tcp(%s)/%s?parseTime=true", cfg.Username, cfg.Password, cfg.Host, cfg.Name))
+	if err != nil {
+		return nil, err
+	}
+
+	return db, nil
+}
 
-	db, err := sql.Open("mysql", fmt.Sprintf("%s:%s@tcp(%s)/%s", username, password, host, database))
+func connectSQLite(cfg config.DBConfig) (*sql.DB, error) {
+	db, err := sql.Open("sqlite", fmt.Sprintf("file:%s?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_pragma=foreign_keys(1)&_txlock=immediate", cfg.Path))
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/morf1lo/blog-app/internal/models"
+	"github.com/morf1lo/blog-app/internal/utils"
+)
+
+func (h *Handler) createAccessToken(c *gin.Context) {
+	user := utils.GetUserFromRequest(c)
+
+	var options models.AccessTokenOptions
+	if err := c.ShouldBindJSON(&options); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := options.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	token, err := h.services.AccessToken.CreateAccessToken(user.ID, options)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "data": token})
+}
+
+func (h *Handler) getAccessTokens(c *gin.Context) {
+	user := utils.GetUserFromRequest(c)
+
+	tokens, err := h.services.AccessToken.FindAccessTokens(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "data": tokens})
+}
+
+func (h *Handler) getAccessTokenScopes(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"success": true, "data": models.Scopes})
+}
+
+func (h *Handler) revokeAccessToken(c *gin.Context) {
+	user := utils.GetUserFromRequest(c)
+
+	tokenID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.services.AccessToken.RevokeAccessToken(int64(tokenID), user.ID); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
@@ -2,7 +2,6 @@ package handler
 
 import (
 	"net/http"
-	"os"
 	"strings"
 	"time"
 
@@ -13,6 +12,10 @@ import (
 	"github.com/morf1lo/blog-app/internal/utils/auth"
 )
 
+// tokenResponseMode is the ?mode= value with which clients that cannot keep
+// cookies get the tokens in the response body instead.
+const tokenResponseMode = "token"
+
 func (h *Handler) signUp(c *gin.Context) {
 	var user models.User
 
@@ -48,17 +51,18 @@ func (h *Handler) signUp(c *gin.Context) {
 		return
 	}
 
-	if err := h.services.Mail.SendActivationLink([]string{user.Email}, os.Getenv("SERVER_URL") + "/api/auth/activate/" + activationLink.String()); err != nil {
+	if err := h.services.Mail.SendActivationLink([]string{user.Email}, h.cfg.Server.URL + "/api/auth/activate/" + activationLink.String()); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	if err := auth.CreateSendToken(c, userID); err != nil {
+	tokens, err := h.services.Authorization.CreateSession(userID, c.Request.UserAgent(), c.ClientIP())
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"success": true})
+	h.sendTokens(c, tokens, c.Query("mode") == tokenResponseMode)
 }
 
 func (h *Handler) activate(c *gin.Context) {
@@ -86,11 +90,97 @@ func (h *Handler) signIn(c *gin.Context) {
 		return
 	}
 
-	if err := auth.CreateSendToken(c, userID); err != nil {
+	twoFactorEnabled, err := h.services.TwoFactor.IsTwoFactorEnabled(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	// The session is only created once the code is checked by verifyTwoFactor
+	if twoFactorEnabled {
+		challenge, expiresAt, err := h.services.TwoFactor.CreateTwoFactorChallenge(userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"success": true, "data": gin.H{"two_factor_required": true, "challenge": challenge, "expires_at": expiresAt}})
+		return
+	}
+
+	tokens, err := h.services.Authorization.CreateSession(userID, c.Request.UserAgent(), c.ClientIP())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	h.sendTokens(c, tokens, c.Query("mode") == tokenResponseMode)
+}
+
+// verifyTwoFactor completes a sign-in that returned a two-factor challenge,
+// using a code from an authenticator app or a recovery code.
+func (h *Handler) verifyTwoFactor(c *gin.Context) {
+	var request struct {
+		Challenge string `json:"challenge" binding:"required"`
+		Code      string `json:"code" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, err := h.services.TwoFactor.VerifyTwoFactorChallenge(request.Challenge, request.Code)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	tokens, err := h.services.Authorization.CreateSession(userID, c.Request.UserAgent(), c.ClientIP())
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
 		return
 	}
 
+	h.sendTokens(c, tokens, c.Query("mode") == tokenResponseMode)
+}
+
+// refresh exchanges a refresh token for a new access token and refresh token.
+// A token sent in the request body is answered in the body as well.
+func (h *Handler) refresh(c *gin.Context) {
+	inBody := c.Query("mode") == tokenResponseMode
+
+	refreshToken, err := c.Cookie(auth.RefreshTokenCookie)
+	if err != nil {
+		var request struct {
+			RefreshToken string `json:"refresh_token" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "User is not authorized"})
+			return
+		}
+		refreshToken = request.RefreshToken
+		inBody = true
+	}
+
+	tokens, err := h.services.Authorization.RefreshSession(refreshToken, c.ClientIP())
+	if err != nil {
+		if !inBody {
+			h.cookies.ClearTokens(c)
+		}
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.sendTokens(c, tokens, inBody)
+}
+
+func (h *Handler) sendTokens(c *gin.Context, tokens *models.SessionTokens, inBody bool) {
+	if inBody {
+		c.JSON(http.StatusOK, gin.H{"success": true, "data": tokens})
+		return
+	}
+
+	h.cookies.SendTokens(c, tokens)
 	c.JSON(http.StatusOK, gin.H{"success": true})
 }
 
@@ -115,7 +205,7 @@ func (h *Handler) requestToResetPassword(c *gin.Context) {
 		return
 	}
 
-	resetLink := os.Getenv("CLIENT_URL") + "/resetpass/" + token
+	resetLink := h.cfg.ClientURL + "/resetpass/" + token
 
 	if err := h.services.Mail.SendResetPasswordLink([]string{request.Email}, resetLink); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
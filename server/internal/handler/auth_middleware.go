@@ -1,40 +1,80 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
-	"os"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/morf1lo/blog-app/internal/models"
+	"github.com/morf1lo/blog-app/internal/utils/auth"
 )
 
+// scope names the resource that personal access tokens need a scope for to
+// use the routes of a group: <resource>:read for GET requests and
+// <resource>:write for everything else.
+func (h *Handler) scope(resource string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set("scope_resource", resource)
+		c.Next()
+	}
+}
+
+// authMiddleware accepts session access tokens and personal access tokens
+// with the scope the route group requires.
 func (h *Handler) authMiddleware(c *gin.Context) {
-	tokenCookie, err := c.Cookie("jwt")
-	if err != nil {
+	h.authenticate(c, true)
+}
+
+// sessionAuthMiddleware guards account security routes, such as managing
+// sessions and tokens, that personal access tokens must not reach.
+func (h *Handler) sessionAuthMiddleware(c *gin.Context) {
+	h.authenticate(c, false)
+}
+
+func (h *Handler) authenticate(c *gin.Context, allowAccessTokens bool) {
+	accessToken, ok := auth.AccessTokenFromRequest(c)
+	if !ok {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "User is not authorized"})
 		c.Abort()
 		return
 	}
 
-	parsedToken, err := jwt.Parse(tokenCookie, func(token *jwt.Token) (interface{}, error) {
-		return []byte(os.Getenv("SECRET")), nil
-	})
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot read authentication token"})
-		c.Abort()
-		return
-	}
+	var userID, sessionID int64
+	if auth.IsPersonalAccessToken(accessToken) {
+		token, err := h.services.AccessToken.AuthenticateAccessToken(accessToken)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			c.Abort()
+			return
+		}
 
-	claims, ok := parsedToken.Claims.(jwt.MapClaims)
-	if !ok || !parsedToken.Valid {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Authentication token is not valid"})
-		c.Abort()
-		return
-	}
+		if !allowAccessTokens || !token.HasScope(requiredScope(c)) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Access token does not have the required scope"})
+			c.Abort()
+			return
+		}
 
-	id := int64(claims["uid"].(float64))
+		userID = token.UserID
+	} else {
+		var err error
+		userID, sessionID, err = h.services.Authorization.Authenticate(accessToken, c.ClientIP())
+		if err != nil {
+			// Expired and revoked tokens get 401 so that clients know to refresh or sign in again
+			if errors.Is(err, jwt.ErrTokenExpired) {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication token has expired"})
+			} else if errors.Is(err, jwt.ErrTokenMalformed) || errors.Is(err, jwt.ErrTokenSignatureInvalid) || errors.Is(err, auth.ErrInvalidToken) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Authentication token is not valid"})
+			} else {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			}
+			c.Abort()
+			return
+		}
+	}
 
-	user, err := h.services.User.FindUserById(id)
+	user, err := h.services.User.FindUserById(userID)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		c.Abort()
@@ -42,5 +82,19 @@ func (h *Handler) authMiddleware(c *gin.Context) {
 	}
 
 	c.Set("user", *user)
+	c.Set("session_id", sessionID)
 	c.Next()
 }
+
+// requiredScope is empty, and matches no token, on routes outside of a scoped group.
+func requiredScope(c *gin.Context) models.Scope {
+	resource := c.GetString("scope_resource")
+	if resource == "" {
+		return ""
+	}
+
+	if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+		return models.Scope(resource + ":read")
+	}
+	return models.Scope(resource + ":write")
+}
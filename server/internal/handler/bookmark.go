@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/morf1lo/blog-app/internal/models"
+	"github.com/morf1lo/blog-app/internal/utils"
+)
+
+func (h *Handler) addBookmark(c *gin.Context) {
+	user := utils.GetUserFromRequest(c)
+
+	postID, err := strconv.Atoi(c.Param("post"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// The body is optional, an empty one bookmarks the post outside of any collection
+	var options models.BookmarkOptions
+	if err := c.ShouldBindJSON(&options); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.services.Bookmark.AddBookmark(int64(postID), user.ID, options.CollectionID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+func (h *Handler) removeBookmark(c *gin.Context) {
+	user := utils.GetUserFromRequest(c)
+
+	postID, err := strconv.Atoi(c.Param("post"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.services.Bookmark.RemoveBookmark(int64(postID), user.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+func (h *Handler) getBookmarks(c *gin.Context) {
+	user := utils.GetUserFromRequest(c)
+
+	pagination, err := parsePagination(c, models.SortNewest, models.SortOldest)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var collectionID *int64
+	if collectionParam := c.Query("collection"); collectionParam != "" {
+		id, err := strconv.ParseInt(collectionParam, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		collectionID = &id
+	}
+
+	bookmarks, err := h.services.Bookmark.FindBookmarks(user.ID, collectionID, pagination)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, pageResponse(bookmarks))
+}
+
+func (h *Handler) getBookmarkCollections(c *gin.Context) {
+	user := utils.GetUserFromRequest(c)
+
+	collections, err := h.services.Bookmark.FindBookmarkCollections(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "data": collections})
+}
+
+func (h *Handler) createBookmarkCollection(c *gin.Context) {
+	user := utils.GetUserFromRequest(c)
+
+	var collection models.BookmarkCollection
+	if err := c.ShouldBindJSON(&collection); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	collection.UserID = user.ID
+	collection.Name = strings.TrimSpace(collection.Name)
+
+	if err := collection.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	created, err := h.services.Bookmark.CreateBookmarkCollection(collection)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "data": created})
+}
+
+func (h *Handler) deleteBookmarkCollection(c *gin.Context) {
+	user := utils.GetUserFromRequest(c)
+
+	collectionID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.services.Bookmark.DeleteBookmarkCollection(int64(collectionID), user.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
@@ -10,6 +11,17 @@ import (
 	"github.com/morf1lo/blog-app/internal/utils"
 )
 
+const (
+	defaultCommentDepth = 3
+	maxCommentDepth     = 10
+	defaultRepliesLimit = 5
+)
+
+var (
+	errInvalidDepth        = fmt.Errorf("depth must be a number between 0 and %d", maxCommentDepth)
+	errInvalidRepliesLimit = fmt.Errorf("replies_limit must be a number between 1 and %d", maxPageLimit)
+)
+
 func (h *Handler) addComment(c *gin.Context) {
 	user := utils.GetUserFromRequest(c)
 
@@ -48,6 +60,8 @@ func (h *Handler) addComment(c *gin.Context) {
 }
 
 func (h *Handler) getAllPostComments(c *gin.Context) {
+	user := utils.GetUserFromRequest(c)
+
 	postIdParam := c.Param("post")
 	postId, err := strconv.Atoi(postIdParam)
 	if err != nil {
@@ -55,18 +69,171 @@ func (h *Handler) getAllPostComments(c *gin.Context) {
 		return
 	}
 
-	comments, err := h.services.Comment.FindAllPostComments(int64(postId))
+	pagination, err := parsePagination(c, models.SortOldest, models.SortNewest)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tree, err := parseCommentTree(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	comments, err := h.services.Comment.FindAllPostComments(int64(postId), user.ID, pagination, tree)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	if comments == nil {
+	if len(comments.Items) == 0 && pagination.Cursor == nil {
 		c.JSON(http.StatusOK, gin.H{"success": true, "data": "This post has no comments yet"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"success": true, "data": comments})
+	c.JSON(http.StatusOK, pageResponse(comments))
+}
+
+func (h *Handler) getCommentReplies(c *gin.Context) {
+	user := utils.GetUserFromRequest(c)
+
+	postIdParam := c.Param("post")
+	postId, err := strconv.Atoi(postIdParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	commentIdParam := c.Param("comment")
+	commentId, err := strconv.Atoi(commentIdParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	pagination, err := parsePagination(c, models.SortOldest, models.SortNewest)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tree, err := parseCommentTree(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	replies, err := h.services.Comment.FindCommentReplies(int64(postId), int64(commentId), user.ID, pagination, tree)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, pageResponse(replies))
+}
+
+// parseCommentTree reads ?depth= (levels of replies to include) and
+// ?replies_limit= (replies per comment on each level).
+func parseCommentTree(c *gin.Context) (models.CommentTreeOptions, error) {
+	tree := models.CommentTreeOptions{
+		Depth: defaultCommentDepth,
+		RepliesLimit: defaultRepliesLimit,
+	}
+
+	if depthParam := c.Query("depth"); depthParam != "" {
+		depth, err := strconv.Atoi(depthParam)
+		if err != nil || depth < 0 || depth > maxCommentDepth {
+			return tree, errInvalidDepth
+		}
+		tree.Depth = depth
+	}
+
+	if limitParam := c.Query("replies_limit"); limitParam != "" {
+		limit, err := strconv.Atoi(limitParam)
+		if err != nil || limit < 1 || limit > maxPageLimit {
+			return tree, errInvalidRepliesLimit
+		}
+		tree.RepliesLimit = limit
+	}
+
+	return tree, nil
+}
+
+func (h *Handler) editComment(c *gin.Context) {
+	user := utils.GetUserFromRequest(c)
+
+	postIdParam := c.Param("post")
+	postId, err := strconv.Atoi(postIdParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	commentIdParam := c.Param("comment")
+	commentId, err := strconv.Atoi(commentIdParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var request struct {
+		Text string `json:"text"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if strings.TrimSpace(request.Text) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Please provide comment text"})
+		return
+	}
+
+	comment := models.Comment{AuthorID: user.ID, Text: request.Text}
+	if err := comment.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.services.Comment.EditComment(int64(commentId), user.ID, int64(postId), request.Text); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+func (h *Handler) getCommentRevisions(c *gin.Context) {
+	user := utils.GetUserFromRequest(c)
+
+	postIdParam := c.Param("post")
+	postId, err := strconv.Atoi(postIdParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	commentIdParam := c.Param("comment")
+	commentId, err := strconv.Atoi(commentIdParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	pagination, err := parsePagination(c, models.SortNewest, models.SortOldest)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	revisions, err := h.services.Comment.FindCommentRevisions(int64(commentId), user.ID, int64(postId), pagination)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, pageResponse(revisions))
 }
 
 func (h *Handler) deleteComment(c *gin.Context) {
@@ -0,0 +1,15 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/morf1lo/blog-app/internal/service"
+)
+
+// errorStatus picks the response status for an error returned by a service.
+func errorStatus(err error) int {
+	if service.IsNotFound(err) {
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
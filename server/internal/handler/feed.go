@@ -0,0 +1,28 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/morf1lo/blog-app/internal/models"
+	"github.com/morf1lo/blog-app/internal/utils"
+)
+
+func (h *Handler) getFeed(c *gin.Context) {
+	user := utils.GetUserFromRequest(c)
+
+	pagination, err := parsePagination(c, models.SortNewest)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	posts, err := h.services.Post.FindFeed(user.ID, pagination)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, pageResponse(posts))
+}
@@ -3,15 +3,24 @@ package handler
 import (
 	"github.com/gin-gonic/gin"
 
+	"github.com/morf1lo/blog-app/internal/config"
 	"github.com/morf1lo/blog-app/internal/service"
+	"github.com/morf1lo/blog-app/internal/utils/auth"
 )
 
 type Handler struct {
 	services *service.Service
+	cfg      *config.Config
+	cookies  auth.Cookies
 }
 
-func NewHandler(services *service.Service) *Handler {
-	return &Handler{services: services}
+func NewHandler(services *service.Service, cfg *config.Config) *Handler {
+	cookies := auth.Cookies{
+		Domain: cfg.Auth.Cookie.Domain,
+		SameSite: cfg.Auth.Cookie.SameSiteMode(),
+		Secure: cfg.Auth.Cookie.Secure,
+	}
+	return &Handler{services: services, cfg: cfg, cookies: cookies}
 }
 
 func (h *Handler) SetupRoutes(router *gin.Engine) {
@@ -20,38 +29,106 @@ func (h *Handler) SetupRoutes(router *gin.Engine) {
 		auth.POST("/signup", h.signUp)
 		auth.GET("/activate/:link", h.activate)
 		auth.POST("/signin", h.signIn)
+		auth.POST("/2fa", h.verifyTwoFactor)
+		auth.POST("/refresh", h.refresh)
 		auth.POST("/reset", h.requestToResetPassword)
 		auth.POST("/reset-pass/:token", h.resetPassword)
 	}
 
-	user := router.Group("/api/users")
+	user := router.Group("/api/users", h.scope("users"))
 	{
-		user.POST("/logout", h.authMiddleware, h.logout)
+		user.POST("/logout", h.sessionAuthMiddleware, h.logout)
+		user.GET("/sessions", h.sessionAuthMiddleware, h.getSessions)
+		user.DELETE("/sessions/others", h.sessionAuthMiddleware, h.revokeOtherSessions)
+		user.DELETE("/sessions/:id", h.sessionAuthMiddleware, h.revokeSession)
+		user.GET("/2fa", h.sessionAuthMiddleware, h.getTwoFactorStatus)
+		user.POST("/2fa/enroll", h.sessionAuthMiddleware, h.enrollTwoFactor)
+		user.POST("/2fa/confirm", h.sessionAuthMiddleware, h.confirmTwoFactor)
+		user.DELETE("/2fa", h.sessionAuthMiddleware, h.disableTwoFactor)
+		user.GET("/me", h.authMiddleware, h.getMe)
 		user.GET("/id/:id", h.authMiddleware, h.getUserById)
 		user.GET("/name/:uname", h.authMiddleware, h.getUserByUsername)
 		user.POST("/avatar", h.authMiddleware, h.setAvatar)
 		user.POST("/follow/:id", h.authMiddleware, h.follow)
+		user.PUT("/:id/follow", h.authMiddleware, h.addFollow)
+		user.DELETE("/:id/follow", h.authMiddleware, h.removeFollow)
 		user.GET("/:id/followers", h.authMiddleware, h.getUserFollowers)
 		user.GET("/:id/follows", h.authMiddleware, h.getUserFollows)
-		user.DELETE("/delete", h.authMiddleware, h.deleteUser)
+		user.DELETE("/delete", h.sessionAuthMiddleware, h.deleteUser)
 	}
 
-	post := router.Group("/api/posts")
+	token := router.Group("/api/tokens")
+	{
+		token.GET("", h.sessionAuthMiddleware, h.getAccessTokens)
+		token.POST("", h.sessionAuthMiddleware, h.createAccessToken)
+		token.GET("/scopes", h.sessionAuthMiddleware, h.getAccessTokenScopes)
+		token.DELETE("/:id", h.sessionAuthMiddleware, h.revokeAccessToken)
+	}
+
+	post := router.Group("/api/posts", h.scope("posts"))
 	{
 		post.POST("/create", h.authMiddleware, h.createPost)
 		post.GET("/:id", h.authMiddleware, h.getPostById)
 		post.GET("/user/:id", h.authMiddleware, h.getAuthorPosts)
 		post.PATCH("/:id", h.authMiddleware, h.updatePost)
 		post.POST("/like/:id", h.authMiddleware, h.likePost)
+		post.PUT("/:id/like", h.authMiddleware, h.addLike)
+		post.DELETE("/:id/like", h.authMiddleware, h.removeLike)
+		post.PUT("/:id/reactions/:reaction", h.authMiddleware, h.reactToPost)
+		post.DELETE("/:id/reactions/:reaction", h.authMiddleware, h.unreactToPost)
 		post.GET("/my/likes", h.authMiddleware, h.getUserLikes)
+		post.GET("/my/drafts", h.authMiddleware, h.getUserDrafts)
+		post.GET("/my/scheduled", h.authMiddleware, h.getUserScheduledPosts)
 		post.DELETE("/:id", h.authMiddleware, h.deletePost)
 		post.GET("/search", h.authMiddleware, h.searchPosts)
+		post.GET("/:id/revisions", h.authMiddleware, h.getPostRevisions)
+		post.GET("/:id/revisions/diff", h.authMiddleware, h.diffPostRevisions)
+		post.POST("/:id/revisions/:revision/restore", h.authMiddleware, h.restorePostRevision)
+	}
+
+	tag := router.Group("/api/tags", h.scope("posts"))
+	{
+		tag.GET("/popular", h.authMiddleware, h.getPopularTags)
+		tag.GET("/:tag/posts", h.authMiddleware, h.getTagPosts)
+	}
+
+	bookmark := router.Group("/api/bookmarks", h.scope("bookmarks"))
+	{
+		bookmark.GET("", h.authMiddleware, h.getBookmarks)
+		bookmark.PUT("/:post", h.authMiddleware, h.addBookmark)
+		bookmark.DELETE("/:post", h.authMiddleware, h.removeBookmark)
+		bookmark.GET("/collections", h.authMiddleware, h.getBookmarkCollections)
+		bookmark.POST("/collections", h.authMiddleware, h.createBookmarkCollection)
+		bookmark.DELETE("/collections/:id", h.authMiddleware, h.deleteBookmarkCollection)
 	}
 
-	comment := router.Group("/api/comments")
+	notification := router.Group("/api/notifications", h.scope("notifications"))
+	{
+		notification.GET("", h.authMiddleware, h.getNotifications)
+		notification.GET("/unread", h.authMiddleware, h.getUnreadNotificationCount)
+		notification.POST("/:id/read", h.authMiddleware, h.markNotificationRead)
+		notification.POST("/read-all", h.authMiddleware, h.markAllNotificationsRead)
+	}
+
+	feed := router.Group("/api/feed", h.scope("posts"))
+	{
+		feed.GET("", h.authMiddleware, h.getFeed)
+	}
+
+	comment := router.Group("/api/comments", h.scope("comments"))
 	{
 		comment.POST("/add/:post", h.authMiddleware, h.addComment)
 		comment.GET("/:post", h.authMiddleware, h.getAllPostComments)
+		comment.GET("/:post/:comment/replies", h.authMiddleware, h.getCommentReplies)
+		comment.PATCH("/:post/:comment", h.authMiddleware, h.editComment)
+		comment.GET("/:post/:comment/revisions", h.authMiddleware, h.getCommentRevisions)
 		comment.DELETE("/:post/:comment", h.authMiddleware, h.deleteComment)
+		comment.PUT("/:post/:comment/reactions/:reaction", h.authMiddleware, h.reactToComment)
+		comment.DELETE("/:post/:comment/reactions/:reaction", h.authMiddleware, h.unreactToComment)
+	}
+
+	reaction := router.Group("/api/reactions", h.scope("posts"))
+	{
+		reaction.GET("", h.authMiddleware, h.getReactions)
 	}
 }
@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/morf1lo/blog-app/internal/models"
+	"github.com/morf1lo/blog-app/internal/utils"
+)
+
+func (h *Handler) getNotifications(c *gin.Context) {
+	user := utils.GetUserFromRequest(c)
+
+	pagination, err := parsePagination(c, models.SortNewest)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	notifications, err := h.services.Notification.FindNotifications(user.ID, pagination)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	unread, err := h.services.Notification.CountUnreadNotifications(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	response := pageResponse(notifications)
+	response["unread"] = unread
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *Handler) getUnreadNotificationCount(c *gin.Context) {
+	user := utils.GetUserFromRequest(c)
+
+	unread, err := h.services.Notification.CountUnreadNotifications(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "data": gin.H{"unread": unread}})
+}
+
+func (h *Handler) markNotificationRead(c *gin.Context) {
+	user := utils.GetUserFromRequest(c)
+
+	notificationID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.services.Notification.MarkNotificationRead(int64(notificationID), user.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+func (h *Handler) markAllNotificationsRead(c *gin.Context) {
+	user := utils.GetUserFromRequest(c)
+
+	if err := h.services.Notification.MarkAllNotificationsRead(user.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/morf1lo/blog-app/internal/models"
+)
+
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
+var (
+	errInvalidLimit  = fmt.Errorf("limit must be a number between 1 and %d", maxPageLimit)
+	errInvalidCursor = errors.New("invalid cursor")
+)
+
+// parsePagination reads ?limit=&cursor=&sort= from the request.
+// The first allowed sort is used when the client does not specify one.
+func parsePagination(c *gin.Context, allowed ...models.Sort) (models.Pagination, error) {
+	pagination := models.Pagination{
+		Limit: defaultPageLimit,
+		Sort: allowed[0],
+	}
+
+	limit, err := parseLimit(c)
+	if err != nil {
+		return pagination, err
+	}
+	pagination.Limit = limit
+
+	if sortParam := c.Query("sort"); sortParam != "" {
+		sort, ok := findSort(models.Sort(sortParam), allowed)
+		if !ok {
+			return pagination, fmt.Errorf("sort must be one of %v", allowed)
+		}
+		pagination.Sort = sort
+	}
+
+	if cursorParam := c.Query("cursor"); cursorParam != "" {
+		cursor, err := models.DecodeCursor(cursorParam)
+		if err != nil || cursor.Sort != pagination.Sort {
+			return pagination, errInvalidCursor
+		}
+		pagination.Cursor = cursor
+	}
+
+	return pagination, nil
+}
+
+// parseLimit reads ?limit= from the request, falling back to the default page size.
+func parseLimit(c *gin.Context) (int, error) {
+	limitParam := c.Query("limit")
+	if limitParam == "" {
+		return defaultPageLimit, nil
+	}
+
+	limit, err := strconv.Atoi(limitParam)
+	if err != nil || limit < 1 || limit > maxPageLimit {
+		return 0, errInvalidLimit
+	}
+	return limit, nil
+}
+
+func findSort(sort models.Sort, allowed []models.Sort) (models.Sort, bool) {
+	for _, s := range allowed {
+		if s == sort {
+			return s, true
+		}
+	}
+	return "", false
+}
+
+func pageResponse[T any](page *models.Page[T]) gin.H {
+	var nextCursor interface{}
+	if page.NextCursor != "" {
+		nextCursor = page.NextCursor
+	}
+
+	return gin.H{"success": true, "data": page.Items, "next_cursor": nextCursor}
+}
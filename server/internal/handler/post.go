@@ -3,6 +3,7 @@ package handler
 import (
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 
@@ -36,6 +37,8 @@ func (h *Handler) createPost(c *gin.Context) {
 }
 
 func (h *Handler) getPostById(c *gin.Context) {
+	user := utils.GetUserFromRequest(c)
+
 	postIDParam := c.Param("id")
 	postID, err := strconv.Atoi(postIDParam)
 	if err != nil {
@@ -43,7 +46,7 @@ func (h *Handler) getPostById(c *gin.Context) {
 		return
 	}
 
-	post, err := h.services.Post.FindPostById(int64(postID))
+	post, err := h.services.Post.FindPostById(int64(postID), user.ID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -53,6 +56,8 @@ func (h *Handler) getPostById(c *gin.Context) {
 }
 
 func (h *Handler) getAuthorPosts(c *gin.Context) {
+	user := utils.GetUserFromRequest(c)
+
 	author := c.Param("id")
 
 	authorInt, err := strconv.Atoi(author)
@@ -61,18 +66,24 @@ func (h *Handler) getAuthorPosts(c *gin.Context) {
 		return
 	}
 
-	posts, err := h.services.Post.FindAuthorPosts(int64(authorInt))
+	pagination, err := parsePagination(c, models.SortNewest, models.SortOldest, models.SortMostLiked)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	posts, err := h.services.Post.FindAuthorPosts(int64(authorInt), user.ID, pagination)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	if posts == nil {
+	if len(posts.Items) == 0 && pagination.Cursor == nil {
 		c.JSON(http.StatusOK, gin.H{"success": true, "data": "This user has no posts yet"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"success": true, "data": posts})
+	c.JSON(http.StatusOK, pageResponse(posts))
 }
 
 func (h *Handler) updatePost(c *gin.Context) {
@@ -92,6 +103,11 @@ func (h *Handler) updatePost(c *gin.Context) {
 		return
 	}
 
+	if err := updateOptions.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	if err := h.services.Post.UpdatePost(updateOptions, int64(postID), user.ID); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -110,7 +126,33 @@ func (h *Handler) likePost(c *gin.Context) {
 			return
 	}
 
-	if err := h.services.Post.LikePost(int64(postId), user.ID); err != nil {
+	liked, err := h.services.Post.LikePost(int64(postId), user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "data": gin.H{"liked": liked}})
+}
+
+func (h *Handler) addLike(c *gin.Context) {
+	h.setLike(c, h.services.Post.AddLike)
+}
+
+func (h *Handler) removeLike(c *gin.Context) {
+	h.setLike(c, h.services.Post.RemoveLike)
+}
+
+func (h *Handler) setLike(c *gin.Context, set func(postID int64, userID int64) error) {
+	user := utils.GetUserFromRequest(c)
+
+	postID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post ID"})
+		return
+	}
+
+	if err := set(int64(postID), user.ID); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -136,26 +178,76 @@ func (h *Handler) deletePost(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"success": true})
 }
 
+func (h *Handler) getUserDrafts(c *gin.Context) {
+	h.getUserPostsByStatus(c, models.PostStatusDraft)
+}
+
+func (h *Handler) getUserScheduledPosts(c *gin.Context) {
+	h.getUserPostsByStatus(c, models.PostStatusScheduled)
+}
+
+func (h *Handler) getUserPostsByStatus(c *gin.Context, status models.PostStatus) {
+	user := utils.GetUserFromRequest(c)
+
+	pagination, err := parsePagination(c, models.SortNewest, models.SortOldest)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	posts, err := h.services.Post.FindUserPostsByStatus(user.ID, status, pagination)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, pageResponse(posts))
+}
+
 func (h *Handler) getUserLikes(c *gin.Context) {
 	user := utils.GetUserFromRequest(c)
 
-	likes, err := h.services.Post.FindUserLikes(user.ID)
+	pagination, err := parsePagination(c, models.SortNewest, models.SortOldest, models.SortMostLiked)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	likes, err := h.services.Post.FindUserLikes(user.ID, pagination)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"success": true, "data": likes})
+	c.JSON(http.StatusOK, pageResponse(likes))
 }
 
 func (h *Handler) searchPosts(c *gin.Context) {
+	user := utils.GetUserFromRequest(c)
+
 	q := c.Query("q")
 
-	posts, err := h.services.Post.SearchPosts(q)
+	var tags []string
+	if tagsParam := c.Query("tags"); tagsParam != "" {
+		tags = strings.Split(tagsParam, ",")
+	}
+
+	pagination, err := parsePagination(c, models.SortRelevance, models.SortMostLiked, models.SortNewest, models.SortOldest)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	// Search pages by offset, which the cursor carries as its value
+	if pagination.Cursor != nil && (pagination.Cursor.Value < 0 || pagination.Cursor.ID != 0) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errInvalidCursor.Error()})
+		return
+	}
+
+	posts, err := h.services.Search.SearchPosts(q, tags, user.ID, pagination)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"success": true, "data": posts})
+	c.JSON(http.StatusOK, pageResponse(posts))
 }
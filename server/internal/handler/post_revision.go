@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/morf1lo/blog-app/internal/models"
+	"github.com/morf1lo/blog-app/internal/utils"
+)
+
+func (h *Handler) getPostRevisions(c *gin.Context) {
+	user := utils.GetUserFromRequest(c)
+
+	postIDParam := c.Param("id")
+	postID, err := strconv.Atoi(postIDParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	pagination, err := parsePagination(c, models.SortNewest, models.SortOldest)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	revisions, err := h.services.Post.FindPostRevisions(int64(postID), user.ID, pagination)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, pageResponse(revisions))
+}
+
+func (h *Handler) diffPostRevisions(c *gin.Context) {
+	user := utils.GetUserFromRequest(c)
+
+	postIDParam := c.Param("id")
+	postID, err := strconv.Atoi(postIDParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var request struct {
+		From int64 `form:"from" binding:"required,min=1"`
+		To   int64 `form:"to" binding:"required,min=1"`
+	}
+	if err := c.ShouldBindQuery(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	revisionDiff, err := h.services.Post.DiffPostRevisions(int64(postID), user.ID, request.From, request.To)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "data": revisionDiff})
+}
+
+func (h *Handler) restorePostRevision(c *gin.Context) {
+	user := utils.GetUserFromRequest(c)
+
+	postIDParam := c.Param("id")
+	postID, err := strconv.Atoi(postIDParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	revisionParam := c.Param("revision")
+	revision, err := strconv.Atoi(revisionParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.services.Post.RestorePostRevision(int64(postID), int64(revision), user.ID); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
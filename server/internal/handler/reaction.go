@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/morf1lo/blog-app/internal/utils"
+)
+
+func (h *Handler) getReactions(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"success": true, "data": h.services.Reaction.ListReactions()})
+}
+
+func (h *Handler) reactToPost(c *gin.Context) {
+	h.setPostReaction(c, h.services.Reaction.ReactToPost)
+}
+
+func (h *Handler) unreactToPost(c *gin.Context) {
+	h.setPostReaction(c, h.services.Reaction.UnreactToPost)
+}
+
+func (h *Handler) setPostReaction(c *gin.Context, set func(postID int64, userID int64, reaction string) error) {
+	user := utils.GetUserFromRequest(c)
+
+	postID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := set(int64(postID), user.ID, c.Param("reaction")); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+func (h *Handler) reactToComment(c *gin.Context) {
+	h.setCommentReaction(c, h.services.Reaction.ReactToComment)
+}
+
+func (h *Handler) unreactToComment(c *gin.Context) {
+	h.setCommentReaction(c, h.services.Reaction.UnreactToComment)
+}
+
+func (h *Handler) setCommentReaction(c *gin.Context, set func(postID int64, commentID int64, userID int64, reaction string) error) {
+	user := utils.GetUserFromRequest(c)
+
+	postID, err := strconv.Atoi(c.Param("post"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	commentID, err := strconv.Atoi(c.Param("comment"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := set(int64(postID), int64(commentID), user.ID, c.Param("reaction")); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/morf1lo/blog-app/internal/utils"
+)
+
+func (h *Handler) getSessions(c *gin.Context) {
+	user := utils.GetUserFromRequest(c)
+
+	sessions, err := h.services.Authorization.ListSessions(user.ID, c.GetInt64("session_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "data": sessions})
+}
+
+func (h *Handler) revokeSession(c *gin.Context) {
+	user := utils.GetUserFromRequest(c)
+
+	sessionID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.services.Authorization.RevokeSession(int64(sessionID), user.ID); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	if int64(sessionID) == c.GetInt64("session_id") {
+		h.cookies.ClearTokens(c)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+func (h *Handler) revokeOtherSessions(c *gin.Context) {
+	user := utils.GetUserFromRequest(c)
+
+	if err := h.services.Authorization.RevokeOtherSessions(user.ID, c.GetInt64("session_id")); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/morf1lo/blog-app/internal/utils"
+)
+
+func (h *Handler) getTwoFactorStatus(c *gin.Context) {
+	user := utils.GetUserFromRequest(c)
+
+	status, err := h.services.TwoFactor.FindTwoFactorStatus(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "data": status})
+}
+
+func (h *Handler) enrollTwoFactor(c *gin.Context) {
+	user := utils.GetUserFromRequest(c)
+
+	enrollment, err := h.services.TwoFactor.EnrollTwoFactor(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "data": enrollment})
+}
+
+func (h *Handler) confirmTwoFactor(c *gin.Context) {
+	user := utils.GetUserFromRequest(c)
+
+	var request struct {
+		Code string `json:"code" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.services.TwoFactor.ConfirmTwoFactor(user.ID, request.Code); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+func (h *Handler) disableTwoFactor(c *gin.Context) {
+	user := utils.GetUserFromRequest(c)
+
+	var request struct {
+		Password string `json:"password" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.services.TwoFactor.DisableTwoFactor(user.ID, request.Password); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
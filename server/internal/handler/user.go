@@ -6,6 +6,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/morf1lo/blog-app/internal/models"
 	"github.com/morf1lo/blog-app/internal/utils"
 )
 
@@ -29,16 +30,29 @@ func (h *Handler) deleteUser(c *gin.Context) {
 		return
 	}
 
-	c.SetCookie("jwt", "", -1, "/", "localhost", true, true)
+	h.cookies.ClearTokens(c)
 
 	c.JSON(http.StatusOK, gin.H{"success": true})
 }
 
 func (h *Handler) logout(c *gin.Context) {
-	c.SetCookie("jwt", "", -1, "/", "localhost", true, true)
+	user := utils.GetUserFromRequest(c)
+
+	if err := h.services.Authorization.RevokeSession(c.GetInt64("session_id"), user.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.cookies.ClearTokens(c)
 	c.JSON(http.StatusOK, gin.H{"success": true})
 }
 
+func (h *Handler) getMe(c *gin.Context) {
+	user := utils.GetUserFromRequest(c)
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "data": models.Me{User: *user, Role: user.Role}})
+}
+
 func (h *Handler) getUserById(c *gin.Context) {
 	idParam := c.Param("id")
 	id, err := strconv.Atoi(idParam)
@@ -100,7 +114,38 @@ func (h *Handler) follow(c *gin.Context) {
 		return
 	}
 
-	if err := h.services.User.Follow(user.ID, int64(following)); err != nil {
+	followed, err := h.services.User.Follow(user.ID, int64(following))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "data": gin.H{"followed": followed}})
+}
+
+func (h *Handler) addFollow(c *gin.Context) {
+	h.setFollow(c, h.services.User.AddFollow)
+}
+
+func (h *Handler) removeFollow(c *gin.Context) {
+	h.setFollow(c, h.services.User.RemoveFollow)
+}
+
+func (h *Handler) setFollow(c *gin.Context, set func(userID int64, followingID int64) error) {
+	user := utils.GetUserFromRequest(c)
+
+	following, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if user.ID == int64(following) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot follow yourself"})
+		return
+	}
+
+	if err := set(user.ID, int64(following)); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -116,13 +161,19 @@ func (h *Handler) getUserFollowers(c *gin.Context) {
 		return
 	}
 
-	followers, err := h.services.User.FindUserFollowers(int64(userID))
+	pagination, err := parsePagination(c, models.SortNewest, models.SortOldest)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	followers, err := h.services.User.FindUserFollowers(int64(userID), pagination)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"success": true, "data": followers})
+	c.JSON(http.StatusOK, pageResponse(followers))
 }
 
 func (h *Handler) getUserFollows(c *gin.Context) {
@@ -133,11 +184,17 @@ func (h *Handler) getUserFollows(c *gin.Context) {
 		return
 	}
 
-	followers, err := h.services.User.FindUserFollows(int64(userID))
+	pagination, err := parsePagination(c, models.SortNewest, models.SortOldest)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	follows, err := h.services.User.FindUserFollows(int64(userID), pagination)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"success": true, "data": followers})
+	c.JSON(http.StatusOK, pageResponse(follows))
 }
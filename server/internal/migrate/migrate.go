@@ -0,0 +1,339 @@
+package migrate
+
+import (
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/morf1lo/blog-app/internal/db"
+)
+
+//go:embed migrations/*/*.sql
+var migrationsFS embed.FS
+
+var fileNameRegexp = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+var errNothingToRollback = errors.New("no applied migrations to roll back")
+
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus describes a migration in the database. A dirty migration
+// failed part way without being rolled back, see Migrator.
+type MigrationStatus struct {
+	Version   int64
+	Name      string
+	Applied   bool
+	AppliedAt string
+	Dirty     bool
+}
+
+type appliedMigration struct {
+	appliedAt string
+	dirty     bool
+}
+
+// Migrator applies and rolls back migrations, recording them in schema_migrations.
+//
+// On SQLite each migration runs in a transaction together with its bookkeeping,
+// so it is applied completely or not at all. MySQL commits implicitly after
+// most DDL statements, so there a migration is recorded as dirty before its
+// statements run one at a time, and only marked clean once all of them
+// succeeded. A failure leaves it dirty, and Up and Down refuse to run until
+// the schema has been repaired by hand.
+type Migrator struct {
+	db            *sql.DB
+	migrations    []Migration
+	transactional bool
+}
+
+func NewMigrator(conn *sql.DB, driver string) (*Migrator, error) {
+	migrations, err := loadMigrations(migrationsFS, "migrations/"+driver)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{db: conn, migrations: migrations, transactional: driver != db.DriverMySQL}, nil
+}
+
+func loadMigrations(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		match := fileNameRegexp.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name: %s", entry.Name())
+		}
+
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		content, err := fs.ReadFile(fsys, dir+"/"+entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has conflicting names: %s and %s", version, migration.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	var migrations []Migration
+	for _, migration := range byVersion {
+		if migration.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up script", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+func (m *Migrator) ensureVersionTable() error {
+	_, err := m.db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version BIGINT NOT NULL PRIMARY KEY,
+		name VARCHAR(255) NOT NULL,
+		applied_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		dirty BOOLEAN NOT NULL DEFAULT FALSE
+	)`)
+	if err != nil {
+		return err
+	}
+
+	// Databases migrated before dirty tracking existed lack the column
+	rows, err := m.db.Query("SELECT dirty FROM schema_migrations WHERE 1 = 0")
+	if err != nil {
+		_, err = m.db.Exec("ALTER TABLE schema_migrations ADD COLUMN dirty BOOLEAN NOT NULL DEFAULT FALSE")
+		return err
+	}
+	return rows.Close()
+}
+
+func (m *Migrator) appliedVersions() (map[int64]appliedMigration, error) {
+	if err := m.ensureVersionTable(); err != nil {
+		return nil, err
+	}
+
+	rows, err := m.db.Query("SELECT version, applied_at, dirty FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int64]appliedMigration)
+	for rows.Next() {
+		var version int64
+		var migration appliedMigration
+		if err := rows.Scan(&version, &migration.appliedAt, &migration.dirty); err != nil {
+			return nil, err
+		}
+		applied[version] = migration
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return applied, nil
+}
+
+// checkClean fails when a migration was left dirty, since running further
+// scripts on a half migrated schema would only make it harder to repair.
+func (m *Migrator) checkClean(applied map[int64]appliedMigration) error {
+	for _, migration := range m.migrations {
+		if applied[migration.Version].dirty {
+			return fmt.Errorf("migration %d_%s is dirty: it failed part way and was not rolled back; "+
+				"repair the schema by hand, then set dirty = FALSE in schema_migrations if it is now applied or delete its row if it is not",
+				migration.Version, migration.Name)
+		}
+	}
+	return nil
+}
+
+// Up applies every pending migration in version order and returns how many were applied.
+func (m *Migrator) Up() (int, error) {
+	applied, err := m.appliedVersions()
+	if err != nil {
+		return 0, err
+	}
+	if err := m.checkClean(applied); err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+
+		if err := m.up(migration); err != nil {
+			return count, fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+		count++
+	}
+
+	return count, nil
+}
+
+// Down rolls back the most recently applied migration.
+func (m *Migrator) Down() (*Migration, error) {
+	applied, err := m.appliedVersions()
+	if err != nil {
+		return nil, err
+	}
+	if err := m.checkClean(applied); err != nil {
+		return nil, err
+	}
+
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		migration := m.migrations[i]
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+
+		if err := m.down(migration); err != nil {
+			return nil, fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+		return &migration, nil
+	}
+
+	return nil, errNothingToRollback
+}
+
+func (m *Migrator) Status() ([]MigrationStatus, error) {
+	applied, err := m.appliedVersions()
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []MigrationStatus
+	for _, migration := range m.migrations {
+		appliedMigration, ok := applied[migration.Version]
+		statuses = append(statuses, MigrationStatus{
+			Version:   migration.Version,
+			Name:      migration.Name,
+			Applied:   ok,
+			AppliedAt: appliedMigration.appliedAt,
+			Dirty:     appliedMigration.dirty,
+		})
+	}
+
+	return statuses, nil
+}
+
+func (m *Migrator) up(migration Migration) error {
+	if m.transactional {
+		return m.applyInTx(migration.Up, "INSERT INTO schema_migrations(version, name) VALUES(?, ?)", migration.Version, migration.Name)
+	}
+
+	if _, err := m.db.Exec("INSERT INTO schema_migrations(version, name, dirty) VALUES(?, ?, ?)", migration.Version, migration.Name, true); err != nil {
+		return err
+	}
+	if err := m.applyStatements(migration.Up); err != nil {
+		return err
+	}
+	_, err := m.db.Exec("UPDATE schema_migrations SET dirty = ? WHERE version = ?", false, migration.Version)
+	return err
+}
+
+func (m *Migrator) down(migration Migration) error {
+	if m.transactional {
+		return m.applyInTx(migration.Down, "DELETE FROM schema_migrations WHERE version = ?", migration.Version)
+	}
+
+	if _, err := m.db.Exec("UPDATE schema_migrations SET dirty = ? WHERE version = ?", true, migration.Version); err != nil {
+		return err
+	}
+	if err := m.applyStatements(migration.Down); err != nil {
+		return err
+	}
+	_, err := m.db.Exec("DELETE FROM schema_migrations WHERE version = ?", migration.Version)
+	return err
+}
+
+func (m *Migrator) applyInTx(script string, trackQuery string, trackArgs ...interface{}) error {
+	tx, err := m.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, statement := range splitStatements(script) {
+		if _, err := tx.Exec(statement); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec(trackQuery, trackArgs...); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// applyStatements runs a script one statement at a time, naming the statement that failed.
+func (m *Migrator) applyStatements(script string) error {
+	for i, statement := range splitStatements(script) {
+		if _, err := m.db.Exec(statement); err != nil {
+			return fmt.Errorf("statement %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+func splitStatements(script string) []string {
+	var statements []string
+	var current strings.Builder
+
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+
+		current.WriteString(line)
+		current.WriteString("\n")
+
+		if strings.HasSuffix(trimmed, ";") {
+			statements = append(statements, strings.TrimSuffix(strings.TrimSpace(current.String()), ";"))
+			current.Reset()
+		}
+	}
+
+	if rest := strings.TrimSpace(current.String()); rest != "" {
+		statements = append(statements, rest)
+	}
+
+	return statements
+}
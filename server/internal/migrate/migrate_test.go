@@ -0,0 +1,103 @@
+package migrate
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/morf1lo/blog-app/internal/config"
+	"github.com/morf1lo/blog-app/internal/db"
+)
+
+func newTestMigrator(t *testing.T, migrations []Migration) *Migrator {
+	t.Helper()
+
+	conn, err := db.Connect(config.DBConfig{Driver: db.DriverSQLite, Path: filepath.Join(t.TempDir(), "test.db")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	// Run the scripts the way they run on MySQL, outside of a transaction
+	return &Migrator{db: conn, migrations: migrations, transactional: false}
+}
+
+func TestFailedMigrationIsLeftDirty(t *testing.T) {
+	m := newTestMigrator(t, []Migration{
+		{Version: 1, Name: "first", Up: "CREATE TABLE a (id INT);", Down: "DROP TABLE a;"},
+		{Version: 2, Name: "broken", Up: "CREATE TABLE b (id INT);\nCREATE TABLE a (id INT);", Down: "DROP TABLE b;"},
+	})
+
+	applied, err := m.Up()
+	if err == nil || applied != 1 {
+		t.Fatalf("Up = %d, %v, want 1 and an error", applied, err)
+	}
+	if !strings.Contains(err.Error(), "statement 2") {
+		t.Errorf("error does not name the failed statement: %v", err)
+	}
+
+	statuses, err := m.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if statuses[0].Dirty || !statuses[1].Applied || !statuses[1].Dirty {
+		t.Fatalf("unexpected statuses: %+v", statuses)
+	}
+
+	if _, err := m.Up(); err == nil || !strings.Contains(err.Error(), "dirty") {
+		t.Errorf("Up over a dirty migration: got %v", err)
+	}
+	if _, err := m.Down(); err == nil || !strings.Contains(err.Error(), "dirty") {
+		t.Errorf("Down over a dirty migration: got %v", err)
+	}
+
+	// Repairing the schema by hand: undo the statement that ran and forget the migration
+	if _, err := m.db.Exec("DROP TABLE b"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.db.Exec("DELETE FROM schema_migrations WHERE version = 2"); err != nil {
+		t.Fatal(err)
+	}
+	m.migrations[1].Up = "CREATE TABLE b (id INT);"
+
+	if applied, err := m.Up(); err != nil || applied != 1 {
+		t.Fatalf("Up after the repair = %d, %v", applied, err)
+	}
+	if _, err := m.Down(); err != nil {
+		t.Fatal(err)
+	}
+
+	statuses, err = m.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !statuses[0].Applied || statuses[1].Applied {
+		t.Fatalf("unexpected statuses after Down: %+v", statuses)
+	}
+}
+
+func TestVersionTableGainsDirtyColumn(t *testing.T) {
+	m := newTestMigrator(t, []Migration{
+		{Version: 1, Name: "first", Up: "CREATE TABLE a (id INT);", Down: "DROP TABLE a;"},
+	})
+
+	_, err := m.db.Exec(`CREATE TABLE schema_migrations (
+		version BIGINT NOT NULL PRIMARY KEY,
+		name VARCHAR(255) NOT NULL,
+		applied_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.db.Exec("INSERT INTO schema_migrations(version, name) VALUES(1, 'first')"); err != nil {
+		t.Fatal(err)
+	}
+
+	statuses, err := m.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !statuses[0].Applied || statuses[0].Dirty {
+		t.Fatalf("unexpected status: %+v", statuses[0])
+	}
+}
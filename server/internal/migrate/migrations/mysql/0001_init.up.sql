@@ -0,0 +1,45 @@
+CREATE TABLE IF NOT EXISTS users (
+	id BIGINT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+	username VARCHAR(16) NOT NULL UNIQUE,
+	email VARCHAR(150) NOT NULL UNIQUE,
+	password VARCHAR(255) NOT NULL,
+	avatar VARCHAR(255) NOT NULL DEFAULT '',
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	activated BOOLEAN NOT NULL DEFAULT FALSE,
+	activation_link VARCHAR(36) NULL,
+	reset_token VARCHAR(64) NULL,
+	reset_token_expiry DATETIME NULL,
+	INDEX idx_users_activation_link (activation_link),
+	INDEX idx_users_reset_token (reset_token)
+);
+
+CREATE TABLE IF NOT EXISTS posts (
+	id BIGINT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+	author_id BIGINT NOT NULL,
+	title VARCHAR(50) NOT NULL,
+	text TEXT NOT NULL,
+	likes BIGINT UNSIGNED NOT NULL DEFAULT 0,
+	INDEX idx_posts_author_id (author_id)
+);
+
+CREATE TABLE IF NOT EXISTS comments (
+	id BIGINT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+	post JSON NOT NULL,
+	author_id BIGINT NOT NULL,
+	text TEXT NOT NULL,
+	INDEX idx_comments_author_id (author_id)
+);
+
+CREATE TABLE IF NOT EXISTS likes (
+	user_id BIGINT NOT NULL,
+	post_id BIGINT NOT NULL,
+	INDEX idx_likes_user_id (user_id),
+	INDEX idx_likes_post_id (post_id)
+);
+
+CREATE TABLE IF NOT EXISTS followers (
+	user_id BIGINT NOT NULL,
+	following_id BIGINT NOT NULL,
+	INDEX idx_followers_user_id (user_id),
+	INDEX idx_followers_following_id (following_id)
+);
@@ -0,0 +1,2 @@
+ALTER TABLE followers DROP COLUMN id;
+ALTER TABLE likes DROP COLUMN id;
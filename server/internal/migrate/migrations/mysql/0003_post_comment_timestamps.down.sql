@@ -0,0 +1,6 @@
+ALTER TABLE comments DROP COLUMN updated_at;
+ALTER TABLE comments DROP COLUMN created_at;
+
+DROP INDEX idx_posts_author_created ON posts;
+ALTER TABLE posts DROP COLUMN updated_at;
+ALTER TABLE posts DROP COLUMN created_at;
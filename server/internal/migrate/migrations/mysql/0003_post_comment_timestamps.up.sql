@@ -0,0 +1,6 @@
+ALTER TABLE posts ADD COLUMN created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP;
+ALTER TABLE posts ADD COLUMN updated_at DATETIME NULL;
+CREATE INDEX idx_posts_author_created ON posts (author_id, created_at);
+
+ALTER TABLE comments ADD COLUMN created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP;
+ALTER TABLE comments ADD COLUMN updated_at DATETIME NULL;
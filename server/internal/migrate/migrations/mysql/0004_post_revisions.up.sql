@@ -0,0 +1,13 @@
+CREATE TABLE post_revisions (
+	id BIGINT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+	post_id BIGINT NOT NULL,
+	revision BIGINT NOT NULL,
+	title VARCHAR(50) NOT NULL,
+	text TEXT NOT NULL,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE KEY uq_post_revisions_post_revision (post_id, revision)
+);
+
+-- Existing posts start their history with their current content
+INSERT INTO post_revisions (post_id, revision, title, text, created_at)
+SELECT id, 1, title, text, COALESCE(updated_at, created_at) FROM posts;
@@ -0,0 +1,3 @@
+ALTER TABLE post_revisions MODIFY text TEXT NOT NULL;
+ALTER TABLE posts MODIFY text TEXT NOT NULL;
+ALTER TABLE posts DROP COLUMN html;
@@ -0,0 +1,6 @@
+ALTER TABLE posts ADD COLUMN html MEDIUMTEXT NULL;
+UPDATE posts SET html = '';
+ALTER TABLE posts MODIFY html MEDIUMTEXT NOT NULL;
+-- TEXT holds 64 KB, less than 20000 characters of multibyte text
+ALTER TABLE posts MODIFY text MEDIUMTEXT NOT NULL;
+ALTER TABLE post_revisions MODIFY text MEDIUMTEXT NOT NULL;
@@ -0,0 +1,4 @@
+ALTER TABLE posts ADD COLUMN status VARCHAR(16) NOT NULL DEFAULT 'published';
+ALTER TABLE posts ADD COLUMN publish_at DATETIME NULL;
+UPDATE posts SET publish_at = created_at;
+CREATE INDEX idx_posts_status_publish_at ON posts(status, publish_at);
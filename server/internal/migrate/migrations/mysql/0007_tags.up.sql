@@ -0,0 +1,13 @@
+CREATE TABLE tags (
+	id BIGINT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+	name VARCHAR(32) NOT NULL,
+	UNIQUE KEY uq_tags_name (name)
+);
+
+CREATE TABLE post_tags (
+	post_id BIGINT NOT NULL,
+	tag_id BIGINT NOT NULL,
+	PRIMARY KEY (post_id, tag_id)
+);
+
+CREATE INDEX idx_post_tags_tag_id ON post_tags (tag_id);
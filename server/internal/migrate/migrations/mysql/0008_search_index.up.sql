@@ -0,0 +1,11 @@
+CREATE TABLE search_index (
+	post_id BIGINT NOT NULL,
+	source VARCHAR(16) NOT NULL,
+	source_id BIGINT NOT NULL,
+	term VARCHAR(64) NOT NULL,
+	position INT NOT NULL
+);
+
+CREATE INDEX idx_search_index_term ON search_index (term);
+CREATE INDEX idx_search_index_post_id ON search_index (post_id);
+CREATE INDEX idx_search_index_source ON search_index (source, source_id);
@@ -0,0 +1,3 @@
+DROP INDEX idx_comments_parent_id ON comments;
+ALTER TABLE comments DROP COLUMN deleted;
+ALTER TABLE comments DROP COLUMN parent_id;
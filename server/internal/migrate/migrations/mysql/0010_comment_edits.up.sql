@@ -0,0 +1,11 @@
+ALTER TABLE users ADD COLUMN role VARCHAR(16) NOT NULL DEFAULT 'user';
+ALTER TABLE comments ADD COLUMN edited_at DATETIME NULL;
+
+CREATE TABLE comment_revisions (
+	id BIGINT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+	comment_id BIGINT NOT NULL,
+	text TEXT NOT NULL,
+	created_at DATETIME NOT NULL
+);
+
+CREATE INDEX idx_comment_revisions_comment_id ON comment_revisions (comment_id);
@@ -0,0 +1,6 @@
+ALTER TABLE comments ADD COLUMN post JSON NULL AFTER id;
+UPDATE comments c JOIN posts p ON p.id = c.post_id SET c.post = JSON_OBJECT('id', c.post_id, 'author', p.author_id);
+ALTER TABLE comments MODIFY post JSON NOT NULL;
+ALTER TABLE comments DROP FOREIGN KEY fk_comments_post_id;
+DROP INDEX idx_comments_post_id ON comments;
+ALTER TABLE comments DROP COLUMN post_id;
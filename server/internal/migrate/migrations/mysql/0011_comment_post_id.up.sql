@@ -0,0 +1,12 @@
+ALTER TABLE comments ADD COLUMN post_id BIGINT NULL AFTER id;
+UPDATE comments SET post_id = JSON_EXTRACT(post, '$.id');
+
+-- Comments left behind by deleted posts cannot satisfy the foreign key
+DELETE FROM comment_revisions WHERE comment_id IN (SELECT id FROM comments WHERE post_id IS NULL OR post_id NOT IN (SELECT id FROM posts));
+DELETE FROM search_index WHERE source = 'comment' AND source_id IN (SELECT id FROM comments WHERE post_id IS NULL OR post_id NOT IN (SELECT id FROM posts));
+DELETE FROM comments WHERE post_id IS NULL OR post_id NOT IN (SELECT id FROM posts);
+
+ALTER TABLE comments MODIFY post_id BIGINT NOT NULL;
+CREATE INDEX idx_comments_post_id ON comments (post_id, parent_id);
+ALTER TABLE comments ADD CONSTRAINT fk_comments_post_id FOREIGN KEY (post_id) REFERENCES posts (id);
+ALTER TABLE comments DROP COLUMN post;
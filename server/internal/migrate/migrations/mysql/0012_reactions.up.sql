@@ -0,0 +1,11 @@
+CREATE TABLE reactions (
+	id BIGINT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+	user_id BIGINT NOT NULL,
+	target_type VARCHAR(16) NOT NULL,
+	target_id BIGINT NOT NULL,
+	reaction VARCHAR(32) NOT NULL,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE KEY uq_reactions_user_target_reaction (user_id, target_type, target_id, reaction)
+);
+
+CREATE INDEX idx_reactions_target ON reactions (target_type, target_id);
@@ -0,0 +1,8 @@
+DELETE l FROM likes l JOIN likes d ON d.user_id = l.user_id AND d.post_id = l.post_id AND d.id < l.id;
+DELETE f FROM followers f JOIN followers d ON d.user_id = f.user_id AND d.following_id = f.following_id AND d.id < f.id;
+
+-- Counters may have drifted while duplicates were possible
+UPDATE posts p SET likes = (SELECT COUNT(*) FROM likes l WHERE l.post_id = p.id);
+
+CREATE UNIQUE INDEX uq_likes_user_post ON likes (user_id, post_id);
+CREATE UNIQUE INDEX uq_followers_user_following ON followers (user_id, following_id);
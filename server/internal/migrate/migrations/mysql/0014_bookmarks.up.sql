@@ -0,0 +1,20 @@
+CREATE TABLE bookmark_collections (
+	id BIGINT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+	user_id BIGINT NOT NULL,
+	name VARCHAR(50) NOT NULL,
+	created_at DATETIME NOT NULL,
+	UNIQUE KEY uq_bookmark_collections_user_name (user_id, name)
+);
+
+CREATE TABLE bookmarks (
+	id BIGINT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+	user_id BIGINT NOT NULL,
+	post_id BIGINT NOT NULL,
+	collection_id BIGINT NULL,
+	created_at DATETIME NOT NULL,
+	UNIQUE KEY uq_bookmarks_user_post (user_id, post_id),
+	INDEX idx_bookmarks_post_id (post_id),
+	INDEX idx_bookmarks_collection_id (collection_id),
+	CONSTRAINT fk_bookmarks_post_id FOREIGN KEY (post_id) REFERENCES posts (id),
+	CONSTRAINT fk_bookmarks_collection_id FOREIGN KEY (collection_id) REFERENCES bookmark_collections (id)
+);
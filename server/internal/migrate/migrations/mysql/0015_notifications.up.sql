@@ -0,0 +1,22 @@
+CREATE TABLE notifications (
+	id BIGINT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+	user_id BIGINT NOT NULL,
+	kind VARCHAR(16) NOT NULL,
+	actor_id BIGINT NOT NULL,
+	post_id BIGINT NULL,
+	comment_id BIGINT NULL,
+	read_at DATETIME NULL,
+	created_at DATETIME NOT NULL,
+	updated_at DATETIME NOT NULL,
+	INDEX idx_notifications_user_updated (user_id, updated_at),
+	INDEX idx_notifications_post_id (post_id),
+	CONSTRAINT fk_notifications_post_id FOREIGN KEY (post_id) REFERENCES posts (id)
+);
+
+CREATE TABLE notification_actors (
+	notification_id BIGINT NOT NULL,
+	actor_id BIGINT NOT NULL,
+	PRIMARY KEY (notification_id, actor_id),
+	INDEX idx_notification_actors_actor_id (actor_id),
+	CONSTRAINT fk_notification_actors_notification_id FOREIGN KEY (notification_id) REFERENCES notifications (id)
+);
@@ -0,0 +1,19 @@
+CREATE TABLE sessions (
+	id BIGINT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+	user_id BIGINT NOT NULL,
+	created_at DATETIME NOT NULL,
+	revoked_at DATETIME NULL,
+	INDEX idx_sessions_user_id (user_id)
+);
+
+CREATE TABLE refresh_tokens (
+	id BIGINT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+	session_id BIGINT NOT NULL,
+	token_hash CHAR(64) NOT NULL,
+	created_at DATETIME NOT NULL,
+	expires_at DATETIME NOT NULL,
+	used_at DATETIME NULL,
+	UNIQUE KEY uq_refresh_tokens_token_hash (token_hash),
+	INDEX idx_refresh_tokens_session_id (session_id),
+	CONSTRAINT fk_refresh_tokens_session_id FOREIGN KEY (session_id) REFERENCES sessions (id)
+);
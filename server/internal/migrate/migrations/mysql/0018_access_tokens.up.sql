@@ -0,0 +1,14 @@
+CREATE TABLE access_tokens (
+	id BIGINT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+	user_id BIGINT NOT NULL,
+	name VARCHAR(64) NOT NULL,
+	token_prefix VARCHAR(32) NOT NULL,
+	token_hash CHAR(64) NOT NULL,
+	scopes VARCHAR(255) NOT NULL,
+	created_at DATETIME NOT NULL,
+	expires_at DATETIME NOT NULL,
+	last_used_at DATETIME NULL,
+	revoked_at DATETIME NULL,
+	UNIQUE KEY uq_access_tokens_token_hash (token_hash),
+	INDEX idx_access_tokens_user_id (user_id)
+);
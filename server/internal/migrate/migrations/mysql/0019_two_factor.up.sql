@@ -0,0 +1,25 @@
+CREATE TABLE two_factor (
+	user_id BIGINT NOT NULL PRIMARY KEY,
+	secret VARCHAR(64) NOT NULL,
+	last_step BIGINT NOT NULL DEFAULT 0,
+	created_at DATETIME NOT NULL,
+	enabled_at DATETIME NULL
+);
+
+CREATE TABLE recovery_codes (
+	id BIGINT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+	user_id BIGINT NOT NULL,
+	code_hash CHAR(64) NOT NULL,
+	used_at DATETIME NULL,
+	INDEX idx_recovery_codes_user_id (user_id)
+);
+
+CREATE TABLE two_factor_challenges (
+	id BIGINT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+	user_id BIGINT NOT NULL,
+	token_hash CHAR(64) NOT NULL,
+	attempts INT NOT NULL DEFAULT 0,
+	expires_at DATETIME NOT NULL,
+	UNIQUE KEY uq_two_factor_challenges_token_hash (token_hash),
+	INDEX idx_two_factor_challenges_user_id (user_id)
+);
@@ -0,0 +1,2 @@
+ALTER TABLE two_factor DROP COLUMN locked_until;
+ALTER TABLE two_factor DROP COLUMN failed_attempts;
@@ -0,0 +1,2 @@
+ALTER TABLE two_factor ADD COLUMN failed_attempts INT NOT NULL DEFAULT 0;
+ALTER TABLE two_factor ADD COLUMN locked_until DATETIME NULL;
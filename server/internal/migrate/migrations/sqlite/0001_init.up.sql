@@ -0,0 +1,50 @@
+CREATE TABLE users (
+	id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+	username VARCHAR(16) NOT NULL UNIQUE,
+	email VARCHAR(150) NOT NULL UNIQUE,
+	password VARCHAR(255) NOT NULL,
+	avatar VARCHAR(255) NOT NULL DEFAULT '',
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	activated BOOLEAN NOT NULL DEFAULT FALSE,
+	activation_link VARCHAR(36) NULL,
+	reset_token VARCHAR(64) NULL,
+	reset_token_expiry DATETIME NULL
+);
+
+CREATE INDEX idx_users_activation_link ON users (activation_link);
+CREATE INDEX idx_users_reset_token ON users (reset_token);
+
+CREATE TABLE posts (
+	id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+	author_id INTEGER NOT NULL,
+	title VARCHAR(50) NOT NULL,
+	text TEXT NOT NULL,
+	likes INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE INDEX idx_posts_author_id ON posts (author_id);
+
+CREATE TABLE comments (
+	id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+	post TEXT NOT NULL,
+	author_id INTEGER NOT NULL,
+	text TEXT NOT NULL
+);
+
+CREATE INDEX idx_comments_author_id ON comments (author_id);
+
+CREATE TABLE likes (
+	user_id INTEGER NOT NULL,
+	post_id INTEGER NOT NULL
+);
+
+CREATE INDEX idx_likes_user_id ON likes (user_id);
+CREATE INDEX idx_likes_post_id ON likes (post_id);
+
+CREATE TABLE followers (
+	user_id INTEGER NOT NULL,
+	following_id INTEGER NOT NULL
+);
+
+CREATE INDEX idx_followers_user_id ON followers (user_id);
+CREATE INDEX idx_followers_following_id ON followers (following_id);
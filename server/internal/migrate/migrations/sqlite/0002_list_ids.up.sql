@@ -0,0 +1,21 @@
+CREATE TABLE likes_new (
+	id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+	user_id INTEGER NOT NULL,
+	post_id INTEGER NOT NULL
+);
+INSERT INTO likes_new (user_id, post_id) SELECT user_id, post_id FROM likes;
+DROP TABLE likes;
+ALTER TABLE likes_new RENAME TO likes;
+CREATE INDEX idx_likes_user_id ON likes (user_id);
+CREATE INDEX idx_likes_post_id ON likes (post_id);
+
+CREATE TABLE followers_new (
+	id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+	user_id INTEGER NOT NULL,
+	following_id INTEGER NOT NULL
+);
+INSERT INTO followers_new (user_id, following_id) SELECT user_id, following_id FROM followers;
+DROP TABLE followers;
+ALTER TABLE followers_new RENAME TO followers;
+CREATE INDEX idx_followers_user_id ON followers (user_id);
+CREATE INDEX idx_followers_following_id ON followers (following_id);
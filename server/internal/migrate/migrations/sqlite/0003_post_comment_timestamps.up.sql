@@ -0,0 +1,9 @@
+-- SQLite cannot add a column with a non-constant default, so existing rows are backfilled.
+ALTER TABLE posts ADD COLUMN created_at DATETIME NOT NULL DEFAULT '1970-01-01 00:00:00';
+ALTER TABLE posts ADD COLUMN updated_at DATETIME NULL;
+UPDATE posts SET created_at = CURRENT_TIMESTAMP;
+CREATE INDEX idx_posts_author_created ON posts (author_id, created_at);
+
+ALTER TABLE comments ADD COLUMN created_at DATETIME NOT NULL DEFAULT '1970-01-01 00:00:00';
+ALTER TABLE comments ADD COLUMN updated_at DATETIME NULL;
+UPDATE comments SET created_at = CURRENT_TIMESTAMP;
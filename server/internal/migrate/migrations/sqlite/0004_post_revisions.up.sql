@@ -0,0 +1,13 @@
+CREATE TABLE post_revisions (
+	id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+	post_id INTEGER NOT NULL,
+	revision INTEGER NOT NULL,
+	title VARCHAR(50) NOT NULL,
+	text TEXT NOT NULL,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE (post_id, revision)
+);
+
+-- Existing posts start their history with their current content
+INSERT INTO post_revisions (post_id, revision, title, text, created_at)
+SELECT id, 1, title, text, COALESCE(updated_at, created_at) FROM posts;
@@ -0,0 +1 @@
+ALTER TABLE posts ADD COLUMN html TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,3 @@
+DROP INDEX idx_posts_status_publish_at;
+ALTER TABLE posts DROP COLUMN publish_at;
+ALTER TABLE posts DROP COLUMN status;
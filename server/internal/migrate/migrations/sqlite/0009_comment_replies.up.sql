@@ -0,0 +1,3 @@
+ALTER TABLE comments ADD COLUMN parent_id INTEGER NULL;
+ALTER TABLE comments ADD COLUMN deleted BOOLEAN NOT NULL DEFAULT FALSE;
+CREATE INDEX idx_comments_parent_id ON comments (parent_id);
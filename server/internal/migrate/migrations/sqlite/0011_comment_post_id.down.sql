@@ -0,0 +1,20 @@
+CREATE TABLE comments_old (
+	id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+	post TEXT NOT NULL,
+	parent_id INTEGER NULL,
+	author_id INTEGER NOT NULL,
+	text TEXT NOT NULL,
+	deleted BOOLEAN NOT NULL DEFAULT FALSE,
+	created_at DATETIME NOT NULL DEFAULT '1970-01-01 00:00:00',
+	updated_at DATETIME NULL,
+	edited_at DATETIME NULL
+);
+
+INSERT INTO comments_old (id, post, parent_id, author_id, text, deleted, created_at, updated_at, edited_at)
+SELECT c.id, JSON_OBJECT('id', c.post_id, 'author', p.author_id), c.parent_id, c.author_id, c.text, c.deleted, c.created_at, c.updated_at, c.edited_at
+FROM comments c JOIN posts p ON p.id = c.post_id;
+
+DROP TABLE comments;
+ALTER TABLE comments_old RENAME TO comments;
+CREATE INDEX idx_comments_author_id ON comments (author_id);
+CREATE INDEX idx_comments_parent_id ON comments (parent_id);
@@ -0,0 +1,24 @@
+CREATE TABLE comments_new (
+	id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+	post_id INTEGER NOT NULL REFERENCES posts (id),
+	parent_id INTEGER NULL,
+	author_id INTEGER NOT NULL,
+	text TEXT NOT NULL,
+	deleted BOOLEAN NOT NULL DEFAULT FALSE,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME NULL,
+	edited_at DATETIME NULL
+);
+
+-- Comments left behind by deleted posts cannot satisfy the foreign key
+INSERT INTO comments_new (id, post_id, parent_id, author_id, text, deleted, created_at, updated_at, edited_at)
+SELECT id, JSON_EXTRACT(post, '$.id'), parent_id, author_id, text, deleted, created_at, updated_at, edited_at FROM comments
+WHERE JSON_EXTRACT(post, '$.id') IN (SELECT id FROM posts);
+DELETE FROM comment_revisions WHERE comment_id NOT IN (SELECT id FROM comments_new);
+DELETE FROM search_index WHERE source = 'comment' AND source_id NOT IN (SELECT id FROM comments_new);
+
+DROP TABLE comments;
+ALTER TABLE comments_new RENAME TO comments;
+CREATE INDEX idx_comments_author_id ON comments (author_id);
+CREATE INDEX idx_comments_parent_id ON comments (parent_id);
+CREATE INDEX idx_comments_post_id ON comments (post_id, parent_id);
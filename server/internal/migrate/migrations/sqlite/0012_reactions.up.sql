@@ -0,0 +1,11 @@
+CREATE TABLE reactions (
+	id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+	user_id INTEGER NOT NULL,
+	target_type VARCHAR(16) NOT NULL,
+	target_id INTEGER NOT NULL,
+	reaction VARCHAR(32) NOT NULL,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE (user_id, target_type, target_id, reaction)
+);
+
+CREATE INDEX idx_reactions_target ON reactions (target_type, target_id);
@@ -0,0 +1,2 @@
+DROP INDEX uq_followers_user_following;
+DROP INDEX uq_likes_user_post;
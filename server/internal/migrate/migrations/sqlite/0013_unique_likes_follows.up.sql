@@ -0,0 +1,8 @@
+DELETE FROM likes WHERE id NOT IN (SELECT MIN(id) FROM likes GROUP BY user_id, post_id);
+DELETE FROM followers WHERE id NOT IN (SELECT MIN(id) FROM followers GROUP BY user_id, following_id);
+
+-- Counters may have drifted while duplicates were possible
+UPDATE posts SET likes = (SELECT COUNT(*) FROM likes l WHERE l.post_id = posts.id);
+
+CREATE UNIQUE INDEX uq_likes_user_post ON likes (user_id, post_id);
+CREATE UNIQUE INDEX uq_followers_user_following ON followers (user_id, following_id);
@@ -0,0 +1,19 @@
+CREATE TABLE bookmark_collections (
+	id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+	user_id INTEGER NOT NULL,
+	name VARCHAR(50) NOT NULL,
+	created_at DATETIME NOT NULL,
+	UNIQUE (user_id, name)
+);
+
+CREATE TABLE bookmarks (
+	id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+	user_id INTEGER NOT NULL,
+	post_id INTEGER NOT NULL REFERENCES posts (id),
+	collection_id INTEGER NULL REFERENCES bookmark_collections (id),
+	created_at DATETIME NOT NULL,
+	UNIQUE (user_id, post_id)
+);
+
+CREATE INDEX idx_bookmarks_post_id ON bookmarks (post_id);
+CREATE INDEX idx_bookmarks_collection_id ON bookmarks (collection_id);
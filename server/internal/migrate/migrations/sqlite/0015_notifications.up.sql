@@ -0,0 +1,22 @@
+CREATE TABLE notifications (
+	id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+	user_id INTEGER NOT NULL,
+	kind VARCHAR(16) NOT NULL,
+	actor_id INTEGER NOT NULL,
+	post_id INTEGER NULL REFERENCES posts (id),
+	comment_id INTEGER NULL,
+	read_at DATETIME NULL,
+	created_at DATETIME NOT NULL,
+	updated_at DATETIME NOT NULL
+);
+
+CREATE INDEX idx_notifications_user_updated ON notifications (user_id, updated_at);
+CREATE INDEX idx_notifications_post_id ON notifications (post_id);
+
+CREATE TABLE notification_actors (
+	notification_id INTEGER NOT NULL REFERENCES notifications (id),
+	actor_id INTEGER NOT NULL,
+	PRIMARY KEY (notification_id, actor_id)
+);
+
+CREATE INDEX idx_notification_actors_actor_id ON notification_actors (actor_id);
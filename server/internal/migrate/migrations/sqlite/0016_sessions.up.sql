@@ -0,0 +1,19 @@
+CREATE TABLE sessions (
+	id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+	user_id INTEGER NOT NULL,
+	created_at DATETIME NOT NULL,
+	revoked_at DATETIME NULL
+);
+
+CREATE INDEX idx_sessions_user_id ON sessions (user_id);
+
+CREATE TABLE refresh_tokens (
+	id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+	session_id INTEGER NOT NULL REFERENCES sessions (id),
+	token_hash CHAR(64) NOT NULL UNIQUE,
+	created_at DATETIME NOT NULL,
+	expires_at DATETIME NOT NULL,
+	used_at DATETIME NULL
+);
+
+CREATE INDEX idx_refresh_tokens_session_id ON refresh_tokens (session_id);
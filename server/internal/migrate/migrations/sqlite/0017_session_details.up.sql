@@ -0,0 +1,3 @@
+ALTER TABLE sessions ADD COLUMN user_agent VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE sessions ADD COLUMN ip VARCHAR(45) NOT NULL DEFAULT '';
+ALTER TABLE sessions ADD COLUMN last_seen_at DATETIME NULL;
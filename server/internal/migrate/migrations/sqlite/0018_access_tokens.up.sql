@@ -0,0 +1,14 @@
+CREATE TABLE access_tokens (
+	id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+	user_id INTEGER NOT NULL,
+	name VARCHAR(64) NOT NULL,
+	token_prefix VARCHAR(32) NOT NULL,
+	token_hash CHAR(64) NOT NULL UNIQUE,
+	scopes VARCHAR(255) NOT NULL,
+	created_at DATETIME NOT NULL,
+	expires_at DATETIME NOT NULL,
+	last_used_at DATETIME NULL,
+	revoked_at DATETIME NULL
+);
+
+CREATE INDEX idx_access_tokens_user_id ON access_tokens (user_id);
@@ -0,0 +1,26 @@
+CREATE TABLE two_factor (
+	user_id INTEGER NOT NULL PRIMARY KEY,
+	secret VARCHAR(64) NOT NULL,
+	last_step BIGINT NOT NULL DEFAULT 0,
+	created_at DATETIME NOT NULL,
+	enabled_at DATETIME NULL
+);
+
+CREATE TABLE recovery_codes (
+	id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+	user_id INTEGER NOT NULL,
+	code_hash CHAR(64) NOT NULL,
+	used_at DATETIME NULL
+);
+
+CREATE INDEX idx_recovery_codes_user_id ON recovery_codes (user_id);
+
+CREATE TABLE two_factor_challenges (
+	id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+	user_id INTEGER NOT NULL,
+	token_hash CHAR(64) NOT NULL UNIQUE,
+	attempts INTEGER NOT NULL DEFAULT 0,
+	expires_at DATETIME NOT NULL
+);
+
+CREATE INDEX idx_two_factor_challenges_user_id ON two_factor_challenges (user_id);
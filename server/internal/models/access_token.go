@@ -0,0 +1,81 @@
+package models
+
+import (
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Scope grants a personal access token read or write access to one group of routes.
+type Scope string
+
+const (
+	ScopePostsRead          Scope = "posts:read"
+	ScopePostsWrite         Scope = "posts:write"
+	ScopeCommentsRead       Scope = "comments:read"
+	ScopeCommentsWrite      Scope = "comments:write"
+	ScopeUsersRead          Scope = "users:read"
+	ScopeUsersWrite         Scope = "users:write"
+	ScopeBookmarksRead      Scope = "bookmarks:read"
+	ScopeBookmarksWrite     Scope = "bookmarks:write"
+	ScopeNotificationsRead  Scope = "notifications:read"
+	ScopeNotificationsWrite Scope = "notifications:write"
+)
+
+var Scopes = []Scope{
+	ScopePostsRead,
+	ScopePostsWrite,
+	ScopeCommentsRead,
+	ScopeCommentsWrite,
+	ScopeUsersRead,
+	ScopeUsersWrite,
+	ScopeBookmarksRead,
+	ScopeBookmarksWrite,
+	ScopeNotificationsRead,
+	ScopeNotificationsWrite,
+}
+
+func (s Scope) Valid() bool {
+	for _, scope := range Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// AccessToken is a named, scoped credential for scripts and other
+// non-interactive clients. Only a hash of the token is stored; the token
+// itself is returned once, when it is created.
+type AccessToken struct {
+	ID         int64      `json:"id"`
+	UserID     int64      `json:"-"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Token      string     `json:"token,omitempty"`
+	Scopes     []Scope    `json:"scopes"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"-"`
+}
+
+func (t *AccessToken) HasScope(scope Scope) bool {
+	for _, granted := range t.Scopes {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
+}
+
+type AccessTokenOptions struct {
+	Name          string  `json:"name" validate:"required,max=64"`
+	Scopes        []Scope `json:"scopes" validate:"required,min=1"`
+	ExpiresInDays int     `json:"expires_in_days" validate:"required,min=1,max=365"`
+}
+
+func (o *AccessTokenOptions) Validate() error {
+	validate := validator.New()
+	return validate.Struct(o)
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// BookmarkCollection is a named group of a user's bookmarks. Bookmarks
+// outside of any collection are kept as well.
+type BookmarkCollection struct {
+	ID        int64     `json:"id"`
+	UserID    int64     `json:"-"`
+	Name      string    `json:"name" validate:"required,max=50"`
+	Bookmarks int64     `json:"bookmarks"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (c *BookmarkCollection) Validate() error {
+	validate := validator.New()
+	return validate.Struct(c)
+}
+
+type BookmarkOptions struct {
+	CollectionID *int64 `json:"collection_id"`
+}
@@ -1,16 +1,45 @@
 package models
 
-import "github.com/go-playground/validator/v10"
+import (
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
 
 type Comment struct {
-	ID       int64   		 `json:"id"`
-	Post     CommentPost `json:"post"`
-	AuthorID int64  		 `json:"author_id" validate:"required"`
-	Text     string 		 `json:"text" validate:"required"`
+	ID          int64           `json:"id"`
+	Post        CommentPost     `json:"post"`
+	ParentID    *int64          `json:"parent_id"`
+	AuthorID    int64           `json:"author_id" validate:"required"`
+	Text        string          `json:"text" validate:"required,max=5000"`
+	Deleted     bool            `json:"deleted"`
+	ReplyCount  int64           `json:"reply_count"`
+	Reactions   []ReactionCount `json:"reactions"`
+	MyReactions []string        `json:"my_reactions"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   *time.Time      `json:"updated_at"`
+	EditedAt    *time.Time      `json:"edited_at"`
+
+	// First page of replies, filled in when a comment tree is requested
+	Replies       []Comment `json:"replies,omitempty"`
+	RepliesCursor string    `json:"replies_cursor,omitempty"`
+}
+
+// CommentRevision is a previous version of an edited comment.
+type CommentRevision struct {
+	ID        int64     `json:"id"`
+	CommentID int64     `json:"comment_id"`
+	Text      string    `json:"text"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type CommentTreeOptions struct {
+	Depth        int
+	RepliesLimit int
 }
 
 type CommentPost struct {
-	ID		   int64 `json:"id"`
+	ID       int64 `json:"id"`
 	AuthorID int64 `json:"author"`
 }
 
@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+type NotificationKind string
+
+const (
+	NotificationFollow  NotificationKind = "follow"
+	NotificationLike    NotificationKind = "like"
+	NotificationComment NotificationKind = "comment"
+	NotificationReply   NotificationKind = "reply"
+	NotificationMention NotificationKind = "mention"
+)
+
+// Notification tells a user that someone acted on them or their content.
+// Unread notifications of the same kind about the same target are coalesced:
+// Actor is the latest of ActorCount distinct users.
+type Notification struct {
+	ID         int64             `json:"id"`
+	UserID     int64             `json:"-"`
+	Kind       NotificationKind  `json:"kind"`
+	Actor      NotificationActor `json:"actor"`
+	ActorCount int64             `json:"actor_count"`
+	PostID     *int64            `json:"post_id"`
+	PostTitle  *string           `json:"post_title"`
+	CommentID  *int64            `json:"comment_id"`
+	Read       bool              `json:"read"`
+	CreatedAt  time.Time         `json:"created_at"`
+	UpdatedAt  time.Time         `json:"updated_at"`
+}
+
+type NotificationActor struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+	Avatar   string `json:"avatar"`
+}
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+type Sort string
+
+const (
+	SortNewest    Sort = "newest"
+	SortOldest    Sort = "oldest"
+	SortMostLiked Sort = "most_liked"
+	SortRelevance Sort = "relevance"
+)
+
+var errInvalidCursor = errors.New("invalid cursor")
+
+type Cursor struct {
+	Sort  Sort  `json:"s"`
+	Value int64 `json:"v,omitempty"`
+	ID    int64 `json:"id"`
+}
+
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func DecodeCursor(encoded string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+
+	var cursor Cursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, errInvalidCursor
+	}
+
+	return &cursor, nil
+}
+
+type Pagination struct {
+	Limit  int
+	Sort   Sort
+	Cursor *Cursor
+}
+
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor"`
+}
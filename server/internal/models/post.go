@@ -1,14 +1,40 @@
 package models
 
-import "github.com/go-playground/validator/v10"
+import (
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type PostStatus string
+
+const (
+	PostStatusDraft     PostStatus = "draft"
+	PostStatusScheduled PostStatus = "scheduled"
+	PostStatusPublished PostStatus = "published"
+	PostStatusArchived  PostStatus = "archived"
+)
 
 type Post struct {
-	ID		         int64	`json:"id"`
-	AuthorID       int64	`json:"author_id" validate:"required"`
-	AuthorUsername string `json:"author_username"`
-	Title	         string	`json:"title" validate:"min=1,max=50,required"`
-	Text	         string	`json:"text" validate:"min=1,max=120,required"`
-	Likes	         uint64	`json:"likes"`
+	ID             int64           `json:"id"`
+	AuthorID       int64           `json:"author_id" validate:"required"`
+	AuthorUsername string          `json:"author_username"`
+	Title          string          `json:"title" validate:"min=1,max=50,required"`
+	Text           string          `json:"text" validate:"min=1,max=20000,required"`
+	HTML           string          `json:"html,omitempty"`
+	Likes          uint64          `json:"likes"`
+	Tags           []string        `json:"tags" validate:"max=10"`
+	Reactions      []ReactionCount `json:"reactions"`
+	MyReactions    []string        `json:"my_reactions"`
+	IsBookmarked   bool            `json:"is_bookmarked"`
+	Status         PostStatus      `json:"status" validate:"omitempty,oneof=draft scheduled published"`
+	PublishAt      *time.Time      `json:"publish_at"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      *time.Time      `json:"updated_at"`
+}
+
+func (p *Post) VisibleTo(userID int64) bool {
+	return p.Status == PostStatusPublished || p.AuthorID == userID
 }
 
 func (p *Post) Validate() error {
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"github.com/morf1lo/blog-app/internal/utils/diff"
+)
+
+type PostRevision struct {
+	ID        int64     `json:"id"`
+	PostID    int64     `json:"post_id"`
+	Revision  int64     `json:"revision"`
+	Title     string    `json:"title"`
+	Text      string    `json:"text"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type PostRevisionDiff struct {
+	From  int64       `json:"from"`
+	To    int64       `json:"to"`
+	Title []diff.Line `json:"title"`
+	Text  []diff.Line `json:"text"`
+}
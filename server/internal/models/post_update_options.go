@@ -1,17 +1,38 @@
 package models
 
-import "strings"
+import (
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
 
 type PostUpdateOptions struct {
-	Title string `json:"title"`
-	Text  string `json:"text"`
+	Title string `json:"title" validate:"max=50"`
+	Text  string `json:"text" validate:"max=20000"`
+	HTML  string `json:"-"`
+
+	Status    PostStatus `json:"status" validate:"omitempty,oneof=draft scheduled published archived"`
+	PublishAt *time.Time `json:"publish_at"`
+
+	// nil leaves the tags untouched, an empty list removes them
+	Tags *[]string `json:"tags" validate:"omitempty,max=10"`
+}
+
+func (u *PostUpdateOptions) ChangesContent() bool {
+	return strings.TrimSpace(u.Title) != "" || strings.TrimSpace(u.Text) != ""
+}
+
+func (u *PostUpdateOptions) Validate() error {
+	validate := validator.New()
+	return validate.Struct(u)
 }
 
 func (u *PostUpdateOptions) FilterUpdateOptions() (string, []interface{}) {
 	query := "UPDATE posts SET"
 	var values []interface{}
 
-	if strings.TrimSpace(u.Title) == "" && strings.TrimSpace(u.Text) == "" {
+	if !u.ChangesContent() && u.Status == "" {
 		return "", nil
 	}
 
@@ -21,8 +42,13 @@ func (u *PostUpdateOptions) FilterUpdateOptions() (string, []interface{}) {
 	}
 
 	if strings.TrimSpace(u.Text) != "" {
-		query += " text = ?,"
-		values = append(values, strings.TrimSpace(u.Text))
+		query += " text = ?, html = ?,"
+		values = append(values, strings.TrimSpace(u.Text), u.HTML)
+	}
+
+	if u.Status != "" {
+		query += " status = ?, publish_at = ?,"
+		values = append(values, u.Status, u.PublishAt)
 	}
 
 	query = strings.TrimSuffix(query, ",")
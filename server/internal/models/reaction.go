@@ -0,0 +1,29 @@
+package models
+
+type ReactionTarget string
+
+const (
+	ReactionTargetPost    ReactionTarget = "post"
+	ReactionTargetComment ReactionTarget = "comment"
+)
+
+// ReactionKind is one of the configured reactions.
+type ReactionKind struct {
+	Name  string `json:"name"`
+	Emoji string `json:"emoji"`
+}
+
+type ReactionCount struct {
+	Name  string `json:"name"`
+	Emoji string `json:"emoji"`
+	Count int64  `json:"count"`
+}
+
+// ReactionTally is the number of reactions of one kind on one post or comment,
+// and whether the viewing user is among them.
+type ReactionTally struct {
+	TargetID int64
+	Name     string
+	Count    int64
+	Mine     bool
+}
@@ -0,0 +1,51 @@
+package models
+
+type SearchSource string
+
+const (
+	SearchSourceTitle   SearchSource = "title"
+	SearchSourceText    SearchSource = "text"
+	SearchSourceAuthor  SearchSource = "author"
+	SearchSourceComment SearchSource = "comment"
+)
+
+// SearchPosting is one occurrence of a term in the search index.
+type SearchPosting struct {
+	PostID   int64
+	Source   SearchSource
+	SourceID int64
+	Term     string
+	Position int
+}
+
+// SearchClause is a condition a post has to meet to match a search: one of
+// Terms occurs in it or, for a phrase, all of Terms occur in a row.
+type SearchClause struct {
+	Terms  []string
+	Phrase bool
+}
+
+type SearchQuery struct {
+	Clauses []SearchClause
+	Tags    []string
+	Sort    Sort
+	Offset  int
+	Limit   int
+}
+
+// SearchMatch is a post matching every clause of a query, with its relevance.
+type SearchMatch struct {
+	PostID int64
+	Score  float64
+}
+
+type SearchResult struct {
+	Post
+	Score     float64         `json:"score"`
+	Highlight SearchHighlight `json:"highlight"`
+}
+
+type SearchHighlight struct {
+	Title   string `json:"title"`
+	Snippet string `json:"snippet"`
+}
@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// Session is one sign-in of a user. Its refresh token is rotated on every
+// use and revoking the session invalidates its access tokens as well.
+type Session struct {
+	ID         int64      `json:"id"`
+	UserID     int64      `json:"-"`
+	UserAgent  string     `json:"user_agent"`
+	IP         string     `json:"ip"`
+	Current    bool       `json:"current"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastSeenAt *time.Time `json:"last_seen_at"`
+	RevokedAt  *time.Time `json:"-"`
+}
+
+type RefreshToken struct {
+	ID        int64
+	SessionID int64
+	UserID    int64
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	Revoked   bool
+}
+
+// SessionTokens are issued on sign-in and on every refresh.
+type SessionTokens struct {
+	UserID           int64     `json:"-"`
+	SessionID        int64     `json:"-"`
+	AccessToken      string    `json:"access_token"`
+	AccessExpiresAt  time.Time `json:"access_expires_at"`
+	RefreshToken     string    `json:"refresh_token"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+}
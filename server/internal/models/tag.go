@@ -0,0 +1,6 @@
+package models
+
+type TagCount struct {
+	Name  string `json:"name"`
+	Posts int64  `json:"posts"`
+}
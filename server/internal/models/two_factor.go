@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// TwoFactor holds a user's TOTP secret. It takes effect once the user
+// confirms enrollment with a code, which sets EnabledAt. Wrong codes entered
+// while signing in are counted across challenges, and too many of them lock
+// sign-in until LockedUntil.
+type TwoFactor struct {
+	UserID         int64
+	Secret         string
+	LastStep       int64
+	FailedAttempts int
+	LockedUntil    *time.Time
+	CreatedAt      time.Time
+	EnabledAt      *time.Time
+}
+
+// TwoFactorEnrollment is shown once, when a user sets up two-factor authentication.
+type TwoFactorEnrollment struct {
+	Secret        string   `json:"secret"`
+	URI           string   `json:"otpauth_uri"`
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type TwoFactorStatus struct {
+	Enabled           bool  `json:"enabled"`
+	RecoveryCodesLeft int64 `json:"recovery_codes_left"`
+}
+
+// TwoFactorChallenge is the pending second step of a sign-in.
+type TwoFactorChallenge struct {
+	ID        int64
+	UserID    int64
+	Attempts  int
+	ExpiresAt time.Time
+}
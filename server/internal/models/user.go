@@ -6,6 +6,13 @@ import (
 	"github.com/go-playground/validator/v10"
 )
 
+type UserRole string
+
+const (
+	UserRoleUser      UserRole = "user"
+	UserRoleModerator UserRole = "moderator"
+)
+
 type User struct {
 	ID				       int64     `json:"id"`
 	Username	       string    `json:"username" validate:"min=3,max=16,required"`
@@ -17,6 +24,17 @@ type User struct {
 	ActivationLink   string    `json:"activation_link"`
 	ResetToken       string    `json:"reset_token"`
 	ResetTokenExpiry time.Time `json:"reset_token_expiry"`
+	Role             UserRole  `json:"-"`
+}
+
+// Me is the signed-in user's own account, the only place their role is shown.
+type Me struct {
+	User
+	Role UserRole `json:"role"`
+}
+
+func (u *User) IsModerator() bool {
+	return u.Role == UserRoleModerator
 }
 
 func (u *User) Validate() error {
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/morf1lo/blog-app/internal/models"
+)
+
+const accessTokenColumns = "id, user_id, name, token_prefix, scopes, created_at, expires_at, last_used_at, revoked_at"
+
+type AccessTokenSQL struct {
+	db *sql.DB
+}
+
+func NewAccessTokenSQL(db *sql.DB) *AccessTokenSQL {
+	return &AccessTokenSQL{db: db}
+}
+
+func (r *AccessTokenSQL) Create(token models.AccessToken, tokenHash string) (int64, error) {
+	result, err := r.db.Exec(
+		"INSERT INTO access_tokens(user_id, name, token_prefix, token_hash, scopes, created_at, expires_at) VALUES(?, ?, ?, ?, ?, ?, ?)",
+		token.UserID, token.Name, token.Prefix, tokenHash, joinScopes(token.Scopes), token.CreatedAt, token.ExpiresAt,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.LastInsertId()
+}
+
+// FindByHash returns the token with the given hash, including revoked and expired ones.
+func (r *AccessTokenSQL) FindByHash(tokenHash string) (*models.AccessToken, error) {
+	return scanAccessToken(r.db.QueryRow("SELECT "+accessTokenColumns+" FROM access_tokens WHERE token_hash = ?", tokenHash))
+}
+
+// FindByUser returns the tokens of a user that have not been revoked, newest first.
+func (r *AccessTokenSQL) FindByUser(userID int64) ([]models.AccessToken, error) {
+	rows, err := r.db.Query("SELECT "+accessTokenColumns+" FROM access_tokens WHERE user_id = ? AND revoked_at IS NULL ORDER BY id DESC", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tokens := []models.AccessToken{}
+	for rows.Next() {
+		token, err := scanAccessToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, *token)
+	}
+
+	return tokens, rows.Err()
+}
+
+// Touch records that a token was used, at most once per staleBefore interval.
+func (r *AccessTokenSQL) Touch(tokenID int64, usedAt time.Time, staleBefore time.Time) error {
+	_, err := r.db.Exec("UPDATE access_tokens SET last_used_at = ? WHERE id = ? AND (last_used_at IS NULL OR last_used_at < ?)", usedAt, tokenID, staleBefore)
+	return err
+}
+
+// Revoke reports false when the user has no such active token.
+func (r *AccessTokenSQL) Revoke(tokenID int64, userID int64, revokedAt time.Time) (bool, error) {
+	result, err := r.db.Exec("UPDATE access_tokens SET revoked_at = ? WHERE id = ? AND user_id = ? AND revoked_at IS NULL", revokedAt, tokenID, userID)
+	if err != nil {
+		return false, err
+	}
+
+	revoked, err := result.RowsAffected()
+	return revoked > 0, err
+}
+
+func scanAccessToken(row interface{ Scan(...interface{}) error }) (*models.AccessToken, error) {
+	var token models.AccessToken
+	var scopes string
+	if err := row.Scan(&token.ID, &token.UserID, &token.Name, &token.Prefix, &scopes, &token.CreatedAt, &token.ExpiresAt, &token.LastUsedAt, &token.RevokedAt); err != nil {
+		return nil, err
+	}
+
+	for _, scope := range strings.Fields(scopes) {
+		token.Scopes = append(token.Scopes, models.Scope(scope))
+	}
+
+	return &token, nil
+}
+
+func joinScopes(scopes []models.Scope) string {
+	names := make([]string, len(scopes))
+	for i, scope := range scopes {
+		names[i] = string(scope)
+	}
+	return strings.Join(names, " ")
+}
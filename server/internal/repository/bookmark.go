@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/morf1lo/blog-app/internal/models"
+)
+
+type BookmarkSQL struct {
+	db *sql.DB
+}
+
+func NewBookmarkSQL(db *sql.DB) *BookmarkSQL {
+	return &BookmarkSQL{db: db}
+}
+
+// Add bookmarks a post, or moves an existing bookmark to another collection.
+func (r *BookmarkSQL) Add(userID int64, postID int64, collectionID *int64, createdAt time.Time) error {
+	result, err := r.db.Exec("UPDATE bookmarks SET collection_id = ? WHERE user_id = ? AND post_id = ?", collectionID, userID, postID)
+	if err != nil {
+		return err
+	}
+
+	updated, err := result.RowsAffected()
+	if err != nil || updated > 0 {
+		return err
+	}
+
+	_, err = r.db.Exec("INSERT INTO bookmarks(user_id, post_id, collection_id, created_at) VALUES(?, ?, ?, ?)", userID, postID, collectionID, createdAt)
+	// Bookmarked by a concurrent request in the meantime
+	if isDuplicateKey(err) {
+		return nil
+	}
+	return err
+}
+
+func (r *BookmarkSQL) Remove(userID int64, postID int64) error {
+	_, err := r.db.Exec("DELETE FROM bookmarks WHERE user_id = ? AND post_id = ?", userID, postID)
+	return err
+}
+
+// FindBookmarkedPosts returns the posts bookmarked by a user, optionally only those in one collection.
+func (r *BookmarkSQL) FindBookmarkedPosts(userID int64, collectionID *int64, pagination models.Pagination) (*models.Page[models.Post], error) {
+	query := "SELECT b.id, " + postColumns + " FROM bookmarks b JOIN posts p ON p.id = b.post_id JOIN users u ON u.id = p.author_id WHERE b.user_id = ? AND (p.status = ? OR p.author_id = b.user_id)"
+	args := []interface{}{userID, models.PostStatusPublished}
+	if collectionID != nil {
+		query += " AND b.collection_id = ?"
+		args = append(args, *collectionID)
+	}
+
+	query, args = newKeyset(pagination, "b.id", "", "").apply(query, args, pagination.Limit)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var posts []models.Post
+	var cursors []models.Cursor
+	for rows.Next() {
+		var bookmarkID int64
+		var post models.Post
+		if err := scanPost(rows, &post, &bookmarkID); err != nil {
+			return nil, err
+		}
+		post.IsBookmarked = true
+		posts = append(posts, post)
+		cursors = append(cursors, postCursor(pagination, bookmarkID, post))
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	page := paginate(posts, cursors, pagination.Limit)
+	if err := loadTags(r.db, page.Items); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// Bookmarked returns which of the given posts the user has bookmarked.
+func (r *BookmarkSQL) Bookmarked(userID int64, postIDs []int64) (map[int64]bool, error) {
+	bookmarked := make(map[int64]bool, len(postIDs))
+	if len(postIDs) == 0 {
+		return bookmarked, nil
+	}
+
+	args := make([]interface{}, 0, len(postIDs)+1)
+	args = append(args, userID)
+	for _, postID := range postIDs {
+		args = append(args, postID)
+	}
+
+	rows, err := r.db.Query("SELECT post_id FROM bookmarks WHERE user_id = ? AND post_id IN ("+placeholders(len(postIDs))+")", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var postID int64
+		if err := rows.Scan(&postID); err != nil {
+			return nil, err
+		}
+		bookmarked[postID] = true
+	}
+
+	return bookmarked, rows.Err()
+}
+
+func (r *BookmarkSQL) CreateCollection(collection models.BookmarkCollection) (int64, error) {
+	result, err := r.db.Exec("INSERT INTO bookmark_collections(user_id, name, created_at) VALUES(?, ?, ?)", collection.UserID, collection.Name, collection.CreatedAt)
+	if err != nil {
+		if isDuplicateKey(err) {
+			return 0, ErrDuplicate
+		}
+		return 0, err
+	}
+
+	return result.LastInsertId()
+}
+
+const collectionColumns = "c.id, c.user_id, c.name, (SELECT COUNT(*) FROM bookmarks b WHERE b.collection_id = c.id), c.created_at"
+
+func scanCollection(row interface{ Scan(...interface{}) error }, collection *models.BookmarkCollection) error {
+	return row.Scan(&collection.ID, &collection.UserID, &collection.Name, &collection.Bookmarks, &collection.CreatedAt)
+}
+
+func (r *BookmarkSQL) FindCollection(collectionID int64) (*models.BookmarkCollection, error) {
+	var collection models.BookmarkCollection
+	if err := scanCollection(r.db.QueryRow("SELECT "+collectionColumns+" FROM bookmark_collections c WHERE c.id = ?", collectionID), &collection); err != nil {
+		return nil, err
+	}
+	return &collection, nil
+}
+
+func (r *BookmarkSQL) FindCollections(userID int64) ([]models.BookmarkCollection, error) {
+	rows, err := r.db.Query("SELECT "+collectionColumns+" FROM bookmark_collections c WHERE c.user_id = ? ORDER BY c.name", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	collections := []models.BookmarkCollection{}
+	for rows.Next() {
+		var collection models.BookmarkCollection
+		if err := scanCollection(rows, &collection); err != nil {
+			return nil, err
+		}
+		collections = append(collections, collection)
+	}
+
+	return collections, rows.Err()
+}
+
+// DeleteCollection removes a collection but keeps its bookmarks, which are
+// left outside of any collection.
+func (r *BookmarkSQL) DeleteCollection(collectionID int64, userID int64) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("UPDATE bookmarks SET collection_id = NULL WHERE collection_id = ? AND user_id = ?", collectionID, userID); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("DELETE FROM bookmark_collections WHERE id = ? AND user_id = ?", collectionID, userID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
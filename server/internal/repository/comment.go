@@ -0,0 +1,278 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/morf1lo/blog-app/internal/models"
+)
+
+type CommentSQL struct {
+	db *sql.DB
+}
+
+func NewCommentSQL(db *sql.DB) *CommentSQL {
+	return &CommentSQL{db: db}
+}
+
+// Comments are selected from "comments c JOIN posts p ON p.id = c.post_id"
+// so that the post author in the payload always reflects the post.
+const commentColumns = "c.id, c.post_id, p.author_id, c.parent_id, c.author_id, c.text, c.deleted, c.created_at, c.updated_at, c.edited_at, (SELECT COUNT(*) FROM comments r WHERE r.parent_id = c.id)"
+
+func scanComment(row interface{ Scan(...interface{}) error }, comment *models.Comment, extra ...interface{}) error {
+	dest := append([]interface{}{&comment.ID, &comment.Post.ID, &comment.Post.AuthorID, &comment.ParentID, &comment.AuthorID, &comment.Text, &comment.Deleted, &comment.CreatedAt, &comment.UpdatedAt, &comment.EditedAt, &comment.ReplyCount}, extra...)
+	return row.Scan(dest...)
+}
+
+func (r *CommentSQL) Create(comment models.Comment) (int64, error) {
+	insertedComment, err := r.db.Exec("INSERT INTO comments (post_id, parent_id, author_id, text, created_at) VALUES(?, ?, ?, ?, ?)", comment.Post.ID, comment.ParentID, comment.AuthorID, comment.Text, comment.CreatedAt)
+	if err != nil {
+		return 0, err
+	}
+
+	return insertedComment.LastInsertId()
+}
+
+func (r *CommentSQL) FindByID(commentID int64) (*models.Comment, error) {
+	var comment models.Comment
+	if err := scanComment(r.db.QueryRow("SELECT "+commentColumns+" FROM comments c JOIN posts p ON p.id = c.post_id WHERE c.id = ?", commentID), &comment); err != nil {
+		return nil, err
+	}
+	return &comment, nil
+}
+
+// FindByPost returns a page of the top-level comments of a post.
+func (r *CommentSQL) FindByPost(postID int64, pagination models.Pagination) (*models.Page[models.Comment], error) {
+	query, args := newKeyset(pagination, "c.id", "", "").apply(
+		"SELECT "+commentColumns+" FROM comments c JOIN posts p ON p.id = c.post_id WHERE c.post_id = ? AND c.parent_id IS NULL",
+		[]interface{}{postID},
+		pagination.Limit,
+	)
+
+	return r.findPage(query, args, pagination)
+}
+
+func (r *CommentSQL) FindReplies(parentID int64, pagination models.Pagination) (*models.Page[models.Comment], error) {
+	query, args := newKeyset(pagination, "c.id", "", "").apply(
+		"SELECT "+commentColumns+" FROM comments c JOIN posts p ON p.id = c.post_id WHERE c.parent_id = ?",
+		[]interface{}{parentID},
+		pagination.Limit,
+	)
+
+	return r.findPage(query, args, pagination)
+}
+
+func (r *CommentSQL) findPage(query string, args []interface{}, pagination models.Pagination) (*models.Page[models.Comment], error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var comments []models.Comment
+	var cursors []models.Cursor
+	for rows.Next() {
+		var comment models.Comment
+		if err := scanComment(rows, &comment); err != nil {
+			return nil, err
+		}
+
+		comments = append(comments, comment)
+		cursors = append(cursors, models.Cursor{Sort: pagination.Sort, ID: comment.ID})
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return paginate(comments, cursors, pagination.Limit), nil
+}
+
+// FindFirstReplies returns the oldest limit replies of each of the given comments
+// with a single query, keyed by parent comment ID.
+func (r *CommentSQL) FindFirstReplies(parentIDs []int64, limit int) (map[int64]*models.Page[models.Comment], error) {
+	pages := make(map[int64]*models.Page[models.Comment], len(parentIDs))
+	if len(parentIDs) == 0 {
+		return pages, nil
+	}
+
+	args := make([]interface{}, 0, len(parentIDs)+1)
+	for _, parentID := range parentIDs {
+		args = append(args, parentID)
+	}
+	args = append(args, limit+1)
+
+	rows, err := r.db.Query(
+		"SELECT "+commentColumns+" FROM (SELECT c.*, ROW_NUMBER() OVER (PARTITION BY c.parent_id ORDER BY c.id) AS reply_number FROM comments c WHERE c.parent_id IN ("+placeholders(len(parentIDs))+")) c JOIN posts p ON p.id = c.post_id WHERE c.reply_number <= ? ORDER BY c.parent_id, c.id",
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	replies := make(map[int64][]models.Comment, len(parentIDs))
+	cursors := make(map[int64][]models.Cursor, len(parentIDs))
+	for rows.Next() {
+		var comment models.Comment
+		if err := scanComment(rows, &comment); err != nil {
+			return nil, err
+		}
+
+		parentID := *comment.ParentID
+		replies[parentID] = append(replies[parentID], comment)
+		cursors[parentID] = append(cursors[parentID], models.Cursor{Sort: models.SortOldest, ID: comment.ID})
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for parentID := range replies {
+		pages[parentID] = paginate(replies[parentID], cursors[parentID], limit)
+	}
+
+	return pages, nil
+}
+
+// Update replaces the text of a comment, keeping the previous text as a revision.
+func (r *CommentSQL) Update(commentID int64, text string, editedAt time.Time) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec("INSERT INTO comment_revisions(comment_id, text, created_at) SELECT id, text, COALESCE(edited_at, created_at) FROM comments WHERE id = ?", commentID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("UPDATE comments SET text = ?, edited_at = ?, updated_at = ? WHERE id = ?", text, editedAt, editedAt, commentID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *CommentSQL) FindRevisions(commentID int64, pagination models.Pagination) (*models.Page[models.CommentRevision], error) {
+	query, args := newKeyset(pagination, "id", "", "").apply(
+		"SELECT id, comment_id, text, created_at FROM comment_revisions WHERE comment_id = ?",
+		[]interface{}{commentID},
+		pagination.Limit,
+	)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var revisions []models.CommentRevision
+	var cursors []models.Cursor
+	for rows.Next() {
+		var revision models.CommentRevision
+		if err := rows.Scan(&revision.ID, &revision.CommentID, &revision.Text, &revision.CreatedAt); err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, revision)
+		cursors = append(cursors, models.Cursor{Sort: pagination.Sort, ID: revision.ID})
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return paginate(revisions, cursors, pagination.Limit), nil
+}
+
+func (r *CommentSQL) FindAuthorID(commentID int64) (int64, error) {
+	var authorID int64
+	if err := r.db.QueryRow("SELECT author_id FROM comments WHERE id = ?", commentID).Scan(&authorID); err != nil {
+		return 0, err
+	}
+	return authorID, nil
+}
+
+// Delete removes a comment. A comment that has replies is replaced by a
+// tombstone so that its replies keep their place in the thread, and
+// tombstones left without replies are removed as well.
+func (r *CommentSQL) Delete(commentID int64, postID int64) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var parentID *int64
+	var replies int64
+	err = tx.QueryRow("SELECT parent_id, (SELECT COUNT(*) FROM comments r WHERE r.parent_id = c.id) FROM comments c WHERE c.id = ? AND c.post_id = ?", commentID, postID).Scan(&parentID, &replies)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if replies > 0 {
+		_, err = tx.Exec("UPDATE comments SET deleted = TRUE, text = '', author_id = 0 WHERE id = ?", commentID)
+	} else {
+		err = deleteWithTombstones(tx, commentID, parentID)
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("DELETE FROM comment_revisions WHERE comment_id = ?", commentID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("DELETE FROM reactions WHERE target_type = ? AND target_id = ?", models.ReactionTargetComment, commentID)
+	if err != nil {
+		return err
+	}
+
+	// Mentions point at the comment text, which is gone even if a tombstone stays
+	_, err = tx.Exec("DELETE FROM notification_actors WHERE notification_id IN (SELECT id FROM notifications WHERE kind = ? AND comment_id = ?)", models.NotificationMention, commentID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("DELETE FROM notifications WHERE kind = ? AND comment_id = ?", models.NotificationMention, commentID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("DELETE FROM search_index WHERE source = ? AND source_id = ?", models.SearchSourceComment, commentID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func deleteWithTombstones(tx *sql.Tx, commentID int64, parentID *int64) error {
+	if _, err := tx.Exec("DELETE FROM comments WHERE id = ?", commentID); err != nil {
+		return err
+	}
+
+	for parentID != nil {
+		var grandparentID *int64
+		err := tx.QueryRow("SELECT parent_id FROM comments c WHERE c.id = ? AND c.deleted = TRUE AND NOT EXISTS(SELECT 1 FROM comments r WHERE r.parent_id = c.id)", *parentID).Scan(&grandparentID)
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec("DELETE FROM comments WHERE id = ?", *parentID); err != nil {
+			return err
+		}
+		parentID = grandparentID
+	}
+
+	return nil
+}
@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/go-sql-driver/mysql"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+const (
+	mysqlDuplicateEntry = 1062
+	mysqlDeadlock       = 1213
+)
+
+// ErrDuplicate is returned when a row would violate a unique constraint.
+var ErrDuplicate = errors.New("duplicate entry")
+
+// isDuplicateKey reports whether err is a unique constraint violation in either driver.
+func isDuplicateKey(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		return mysqlErr.Number == mysqlDuplicateEntry
+	}
+
+	var sqliteErr *sqlite.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE || sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY
+	}
+
+	return false
+}
+
+// isDeadlock reports whether MySQL chose the transaction as a deadlock victim
+// and rolled it back. SQLite serializes writers and has no deadlocks.
+func isDeadlock(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlDeadlock
+}
@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/morf1lo/blog-app/internal/models"
+)
+
+type FollowSQL struct {
+	db *sql.DB
+}
+
+func NewFollowSQL(db *sql.DB) *FollowSQL {
+	return &FollowSQL{db: db}
+}
+
+// Add follows a user and reports whether a follow was added, so following
+// someone twice changes nothing.
+func (r *FollowSQL) Add(userID int64, followingID int64) (bool, error) {
+	return inTx(r.db, func(tx *sql.Tx) (bool, error) {
+		return addFollow(tx, userID, followingID)
+	})
+}
+
+func (r *FollowSQL) Remove(userID int64, followingID int64) (bool, error) {
+	return inTx(r.db, func(tx *sql.Tx) (bool, error) {
+		return removeFollow(tx, userID, followingID)
+	})
+}
+
+// Toggle unfollows the user if followed and follows them otherwise, returning
+// whether the user is followed afterwards.
+func (r *FollowSQL) Toggle(userID int64, followingID int64) (bool, error) {
+	return inTx(r.db, func(tx *sql.Tx) (bool, error) {
+		removed, err := removeFollow(tx, userID, followingID)
+		if err != nil || removed {
+			return false, err
+		}
+
+		// A concurrent request may have followed first, the user is followed either way
+		if _, err := addFollow(tx, userID, followingID); err != nil {
+			return false, err
+		}
+		return true, nil
+	})
+}
+
+func addFollow(tx *sql.Tx, userID int64, followingID int64) (bool, error) {
+	if _, err := tx.Exec("INSERT INTO followers(user_id, following_id) VALUES(?, ?)", userID, followingID); err != nil {
+		if isDuplicateKey(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func removeFollow(tx *sql.Tx, userID int64, followingID int64) (bool, error) {
+	result, err := tx.Exec("DELETE FROM followers WHERE user_id = ? AND following_id = ?", userID, followingID)
+	if err != nil {
+		return false, err
+	}
+
+	removed, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return removed > 0, nil
+}
+
+func (r *FollowSQL) FindFollowers(userID int64, pagination models.Pagination) (*models.Page[models.User], error) {
+	return r.findUsers("SELECT f.id, u.id, u.username, u.avatar FROM followers f JOIN users u ON u.id = f.user_id WHERE f.following_id = ?", userID, pagination)
+}
+
+func (r *FollowSQL) FindFollows(userID int64, pagination models.Pagination) (*models.Page[models.User], error) {
+	return r.findUsers("SELECT f.id, u.id, u.username, u.avatar FROM followers f JOIN users u ON u.id = f.following_id WHERE f.user_id = ?", userID, pagination)
+}
+
+func (r *FollowSQL) findUsers(query string, userID int64, pagination models.Pagination) (*models.Page[models.User], error) {
+	query, args := newKeyset(pagination, "f.id", "", "").apply(query, []interface{}{userID}, pagination.Limit)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []models.User
+	var cursors []models.Cursor
+	for rows.Next() {
+		var followID int64
+		var user models.User
+		if err := rows.Scan(&followID, &user.ID, &user.Username, &user.Avatar); err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+		cursors = append(cursors, models.Cursor{Sort: pagination.Sort, ID: followID})
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return paginate(users, cursors, pagination.Limit), nil
+}
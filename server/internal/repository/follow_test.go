@@ -0,0 +1,51 @@
+package repository
+
+import "testing"
+
+func TestFollowCountsUnderConcurrency(t *testing.T) {
+	conn := newTestDB(t)
+	follows := NewFollowSQL(conn)
+	followerID := createTestUser(t, conn, "follower")
+	followeeID := createTestUser(t, conn, "followee")
+
+	// Follower and following counts are derived from the link table, so the
+	// pair must never be stored more than once
+	assertConsistent := func() int64 {
+		t.Helper()
+		followers := queryInt(t, conn, "SELECT COUNT(*) FROM followers WHERE following_id = ?", followeeID)
+		following := queryInt(t, conn, "SELECT COUNT(*) FROM followers WHERE user_id = ?", followerID)
+		if followers != following || followers > 1 {
+			t.Fatalf("followee has %d followers and follower follows %d users, want equal counts of at most 1", followers, following)
+		}
+		return followers
+	}
+
+	toggled := runConcurrently(t, concurrentCalls, func(int) (bool, error) {
+		return follows.Toggle(followerID, followeeID)
+	})
+	if assertConsistent() != 0 {
+		t.Fatalf("an even number of toggles left the user followed (%d reported following)", countTrue(toggled))
+	}
+
+	runConcurrently(t, 2*concurrentCalls, func(i int) (bool, error) {
+		if i%2 == 0 {
+			return follows.Add(followerID, followeeID)
+		}
+		return follows.Remove(followerID, followeeID)
+	})
+	assertConsistent()
+
+	if _, err := follows.Remove(followerID, followeeID); err != nil {
+		t.Fatal(err)
+	}
+
+	added := runConcurrently(t, concurrentCalls, func(int) (bool, error) {
+		return follows.Add(followerID, followeeID)
+	})
+	if countTrue(added) != 1 {
+		t.Fatalf("%d concurrent adds reported following, want 1", countTrue(added))
+	}
+	if assertConsistent() != 1 {
+		t.Fatal("user is not followed after concurrent adds")
+	}
+}
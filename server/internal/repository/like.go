@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/morf1lo/blog-app/internal/models"
+)
+
+type LikeSQL struct {
+	db *sql.DB
+}
+
+func NewLikeSQL(db *sql.DB) *LikeSQL {
+	return &LikeSQL{db: db}
+}
+
+// Add likes a post and bumps its counter in one transaction. It reports
+// whether a like was added, so liking a post twice changes nothing.
+func (r *LikeSQL) Add(userID int64, postID int64) (bool, error) {
+	return inTx(r.db, func(tx *sql.Tx) (bool, error) {
+		return addLike(tx, userID, postID)
+	})
+}
+
+// Remove is the counterpart of Add and reports whether a like was removed.
+func (r *LikeSQL) Remove(userID int64, postID int64) (bool, error) {
+	return inTx(r.db, func(tx *sql.Tx) (bool, error) {
+		return removeLike(tx, userID, postID)
+	})
+}
+
+// Toggle removes the like if there is one and adds it otherwise, returning
+// whether the post is liked afterwards.
+func (r *LikeSQL) Toggle(userID int64, postID int64) (bool, error) {
+	return inTx(r.db, func(tx *sql.Tx) (bool, error) {
+		removed, err := removeLike(tx, userID, postID)
+		if err != nil || removed {
+			return false, err
+		}
+
+		// A concurrent request may have liked the post first, it is liked either way
+		if _, err := addLike(tx, userID, postID); err != nil {
+			return false, err
+		}
+		return true, nil
+	})
+}
+
+func addLike(tx *sql.Tx, userID int64, postID int64) (bool, error) {
+	if _, err := tx.Exec("INSERT INTO likes(user_id, post_id) VALUES(?, ?)", userID, postID); err != nil {
+		if isDuplicateKey(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	if _, err := tx.Exec("UPDATE posts SET likes = likes + 1 WHERE id = ?", postID); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func removeLike(tx *sql.Tx, userID int64, postID int64) (bool, error) {
+	result, err := tx.Exec("DELETE FROM likes WHERE user_id = ? AND post_id = ?", userID, postID)
+	if err != nil {
+		return false, err
+	}
+
+	removed, err := result.RowsAffected()
+	if err != nil || removed == 0 {
+		return false, err
+	}
+
+	if _, err := tx.Exec("UPDATE posts SET likes = likes - 1 WHERE id = ?", postID); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *LikeSQL) FindLikedPosts(userID int64, pagination models.Pagination) (*models.Page[models.Post], error) {
+	query, args := newKeyset(pagination, "l.id", "p.likes", "").apply(
+		"SELECT l.id, "+postColumns+" FROM likes l JOIN posts p ON p.id = l.post_id JOIN users u ON u.id = p.author_id WHERE l.user_id = ? AND p.status = ?",
+		[]interface{}{userID, models.PostStatusPublished},
+		pagination.Limit,
+	)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var posts []models.Post
+	var cursors []models.Cursor
+	for rows.Next() {
+		var likeID int64
+		var post models.Post
+		if err := scanPost(rows, &post, &likeID); err != nil {
+			return nil, err
+		}
+		posts = append(posts, post)
+		cursors = append(cursors, postCursor(pagination, likeID, post))
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	page := paginate(posts, cursors, pagination.Limit)
+	if err := loadTags(r.db, page.Items); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
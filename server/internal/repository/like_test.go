@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"sync"
+	"testing"
+)
+
+const concurrentCalls = 40
+
+// runConcurrently calls fn from n goroutines at once and fails on any error.
+func runConcurrently(t *testing.T, n int, fn func(i int) (bool, error)) []bool {
+	t.Helper()
+
+	results := make([]bool, n)
+	errs := make([]error, n)
+	start := make(chan struct{})
+
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			results[i], errs[i] = fn(i)
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return results
+}
+
+func countTrue(results []bool) int64 {
+	var count int64
+	for _, result := range results {
+		if result {
+			count++
+		}
+	}
+	return count
+}
+
+// The concurrency tests run on SQLite only, which serializes writers. On MySQL
+// the racing transactions can also deadlock; inTx retries those, which
+// TestInTxRetriesDeadlocks covers, but no test runs them against a MySQL server.
+func TestLikeCounterUnderConcurrency(t *testing.T) {
+	conn := newTestDB(t)
+	likes := NewLikeSQL(conn)
+	userID := createTestUser(t, conn, "liker")
+	postID := createTestPost(t, conn, createTestUser(t, conn, "author"))
+
+	assertConsistent := func() int64 {
+		t.Helper()
+		counter := queryInt(t, conn, "SELECT likes FROM posts WHERE id = ?", postID)
+		rows := queryInt(t, conn, "SELECT COUNT(*) FROM likes WHERE post_id = ?", postID)
+		if counter != rows {
+			t.Fatalf("posts.likes = %d, but there are %d likes", counter, rows)
+		}
+		if rows > 1 {
+			t.Fatalf("user liked the post %d times", rows)
+		}
+		return rows
+	}
+
+	runConcurrently(t, concurrentCalls, func(int) (bool, error) {
+		return likes.Toggle(userID, postID)
+	})
+	if assertConsistent() != 0 {
+		t.Fatal("an even number of toggles left the post liked")
+	}
+
+	runConcurrently(t, 2*concurrentCalls, func(i int) (bool, error) {
+		if i%2 == 0 {
+			return likes.Add(userID, postID)
+		}
+		return likes.Remove(userID, postID)
+	})
+	assertConsistent()
+
+	if _, err := likes.Remove(userID, postID); err != nil {
+		t.Fatal(err)
+	}
+
+	// All but one of the racing inserts hit the unique index
+	added := runConcurrently(t, concurrentCalls, func(int) (bool, error) {
+		return likes.Add(userID, postID)
+	})
+	if countTrue(added) != 1 {
+		t.Fatalf("%d concurrent adds reported adding a like, want 1", countTrue(added))
+	}
+	if assertConsistent() != 1 {
+		t.Fatal("post is not liked after concurrent adds")
+	}
+
+	removed := runConcurrently(t, concurrentCalls, func(int) (bool, error) {
+		return likes.Remove(userID, postID)
+	})
+	if countTrue(removed) != 1 {
+		t.Fatalf("%d concurrent removes reported removing a like, want 1", countTrue(removed))
+	}
+	if assertConsistent() != 0 {
+		t.Fatal("post is still liked after concurrent removes")
+	}
+}
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/morf1lo/blog-app/internal/models"
+)
+
+type NotificationSQL struct {
+	db *sql.DB
+}
+
+func NewNotificationSQL(db *sql.DB) *NotificationSQL {
+	return &NotificationSQL{db: db}
+}
+
+// Record adds the actor of notification to the unread notification of the
+// same kind about the same post and comment, or starts a new one.
+func (r *NotificationSQL) Record(notification models.Notification) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var postID, commentID int64
+	if notification.PostID != nil {
+		postID = *notification.PostID
+	}
+	if notification.CommentID != nil {
+		commentID = *notification.CommentID
+	}
+
+	var notificationID int64
+	err = tx.QueryRow(
+		"SELECT id FROM notifications WHERE user_id = ? AND kind = ? AND COALESCE(post_id, 0) = ? AND COALESCE(comment_id, 0) = ? AND read_at IS NULL ORDER BY id DESC LIMIT 1",
+		notification.UserID, notification.Kind, postID, commentID,
+	).Scan(&notificationID)
+
+	switch {
+	case err == sql.ErrNoRows:
+		result, err := tx.Exec(
+			"INSERT INTO notifications(user_id, kind, actor_id, post_id, comment_id, created_at, updated_at) VALUES(?, ?, ?, ?, ?, ?, ?)",
+			notification.UserID, notification.Kind, notification.Actor.ID, notification.PostID, notification.CommentID, notification.CreatedAt, notification.CreatedAt,
+		)
+		if err != nil {
+			return err
+		}
+		if notificationID, err = result.LastInsertId(); err != nil {
+			return err
+		}
+	case err != nil:
+		return err
+	default:
+		_, err := tx.Exec("UPDATE notifications SET actor_id = ?, updated_at = ? WHERE id = ?", notification.Actor.ID, notification.CreatedAt, notificationID)
+		if err != nil {
+			return err
+		}
+	}
+
+	// The same user liking, unliking and liking again still counts once
+	_, err = tx.Exec("INSERT INTO notification_actors(notification_id, actor_id) VALUES(?, ?)", notificationID, notification.Actor.ID)
+	if err != nil && !isDuplicateKey(err) {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *NotificationSQL) FindByUser(userID int64, pagination models.Pagination) (*models.Page[models.Notification], error) {
+	query, args := newKeyset(pagination, "n.id", "", "n.updated_at").apply(
+		"SELECT n.id, n.user_id, n.kind, u.id, u.username, u.avatar, (SELECT COUNT(*) FROM notification_actors a WHERE a.notification_id = n.id), n.post_id, p.title, n.comment_id, n.read_at IS NOT NULL, n.created_at, n.updated_at FROM notifications n JOIN users u ON u.id = n.actor_id LEFT JOIN posts p ON p.id = n.post_id WHERE n.user_id = ?",
+		[]interface{}{userID},
+		pagination.Limit,
+	)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var notifications []models.Notification
+	var cursors []models.Cursor
+	for rows.Next() {
+		var n models.Notification
+		if err := rows.Scan(&n.ID, &n.UserID, &n.Kind, &n.Actor.ID, &n.Actor.Username, &n.Actor.Avatar, &n.ActorCount, &n.PostID, &n.PostTitle, &n.CommentID, &n.Read, &n.CreatedAt, &n.UpdatedAt); err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, n)
+		cursors = append(cursors, models.Cursor{Sort: pagination.Sort, ID: n.ID, Value: n.UpdatedAt.Unix()})
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return paginate(notifications, cursors, pagination.Limit), nil
+}
+
+func (r *NotificationSQL) CountUnread(userID int64) (int64, error) {
+	var count int64
+	if err := r.db.QueryRow("SELECT COUNT(*) FROM notifications WHERE user_id = ? AND read_at IS NULL", userID).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *NotificationSQL) MarkRead(notificationID int64, userID int64, readAt time.Time) error {
+	_, err := r.db.Exec("UPDATE notifications SET read_at = ? WHERE id = ? AND user_id = ? AND read_at IS NULL", readAt, notificationID, userID)
+	return err
+}
+
+func (r *NotificationSQL) MarkAllRead(userID int64, readAt time.Time) error {
+	_, err := r.db.Exec("UPDATE notifications SET read_at = ? WHERE user_id = ? AND read_at IS NULL", readAt, userID)
+	return err
+}
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/morf1lo/blog-app/internal/models"
+)
+
+type keyset struct {
+	where string
+	args  []interface{}
+	order string
+}
+
+// newKeyset builds the WHERE/ORDER BY fragments for cursor pagination.
+// idColumn must be unique and breaks ties for likesColumn and timeColumn.
+// When timeColumn is empty, chronological sorts fall back to idColumn.
+func newKeyset(pagination models.Pagination, idColumn string, likesColumn string, timeColumn string) keyset {
+	cursor := pagination.Cursor
+
+	switch pagination.Sort {
+	case models.SortOldest:
+		if timeColumn == "" {
+			return singleKeyset(idColumn, "ASC", cursor)
+		}
+		return compoundKeyset(timeColumn, idColumn, "ASC", cursor, cursorTime)
+	case models.SortMostLiked:
+		return compoundKeyset(likesColumn, idColumn, "DESC", cursor, cursorValue)
+	default:
+		if timeColumn == "" {
+			return singleKeyset(idColumn, "DESC", cursor)
+		}
+		return compoundKeyset(timeColumn, idColumn, "DESC", cursor, cursorTime)
+	}
+}
+
+func cursorValue(cursor *models.Cursor) interface{} {
+	return cursor.Value
+}
+
+func cursorTime(cursor *models.Cursor) interface{} {
+	return time.Unix(cursor.Value, 0).UTC()
+}
+
+func comparison(direction string) string {
+	if direction == "ASC" {
+		return " > ?"
+	}
+	return " < ?"
+}
+
+func singleKeyset(idColumn string, direction string, cursor *models.Cursor) keyset {
+	k := keyset{order: idColumn + " " + direction}
+	if cursor != nil {
+		k.where = idColumn + comparison(direction)
+		k.args = []interface{}{cursor.ID}
+	}
+	return k
+}
+
+func compoundKeyset(column string, idColumn string, direction string, cursor *models.Cursor, value func(*models.Cursor) interface{}) keyset {
+	k := keyset{order: column + " " + direction + ", " + idColumn + " " + direction}
+	if cursor != nil {
+		v := value(cursor)
+		k.where = "(" + column + comparison(direction) + " OR (" + column + " = ? AND " + idColumn + comparison(direction) + "))"
+		k.args = []interface{}{v, v, cursor.ID}
+	}
+	return k
+}
+
+// apply appends the keyset conditions, ordering and limit to a query whose
+// WHERE clause has already been started.
+func (k keyset) apply(query string, args []interface{}, limit int) (string, []interface{}) {
+	if k.where != "" {
+		query += " AND " + k.where
+		args = append(args, k.args...)
+	}
+
+	query += " ORDER BY " + k.order + " LIMIT ?"
+	args = append(args, limit+1)
+
+	return query, args
+}
+
+// paginate trims the extra row fetched by keyset.apply and derives the next cursor from it.
+func paginate[T any](items []T, cursors []models.Cursor, limit int) *models.Page[T] {
+	page := &models.Page[T]{Items: items}
+	if len(items) > limit {
+		page.Items = items[:limit]
+		page.NextCursor = cursors[limit-1].Encode()
+	}
+	return page
+}
+
+func postCursor(pagination models.Pagination, id int64, post models.Post) models.Cursor {
+	cursor := models.Cursor{Sort: pagination.Sort, ID: id}
+	if pagination.Sort == models.SortMostLiked {
+		cursor.Value = int64(post.Likes)
+	} else {
+		cursor.Value = postTime(post).Unix()
+	}
+	return cursor
+}
+
+// postTime is the time chronological listings order a post by: when it went
+// live for published posts (p.publish_at) and when it was written otherwise.
+func postTime(post models.Post) time.Time {
+	if post.Status == models.PostStatusPublished && post.PublishAt != nil {
+		return *post.PublishAt
+	}
+	return post.CreatedAt
+}
@@ -0,0 +1,305 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/morf1lo/blog-app/internal/models"
+)
+
+type PostSQL struct {
+	db *sql.DB
+}
+
+func NewPostSQL(db *sql.DB) *PostSQL {
+	return &PostSQL{db: db}
+}
+
+const postColumns = "p.id, p.author_id, u.username, p.title, p.text, p.likes, p.status, p.publish_at, p.created_at, p.updated_at"
+
+func scanPost(row interface{ Scan(...interface{}) error }, post *models.Post, extra ...interface{}) error {
+	dest := append(extra, &post.ID, &post.AuthorID, &post.AuthorUsername, &post.Title, &post.Text, &post.Likes, &post.Status, &post.PublishAt, &post.CreatedAt, &post.UpdatedAt)
+	return row.Scan(dest...)
+}
+
+// insertRevisionQuery records the current title and text of a post of an author as its next revision.
+const insertRevisionQuery = "INSERT INTO post_revisions(post_id, revision, title, text, created_at) SELECT p.id, (SELECT COALESCE(MAX(r.revision), 0) + 1 FROM post_revisions r WHERE r.post_id = p.id), p.title, p.text, ? FROM posts p WHERE p.id = ? AND p.author_id = ?"
+
+// Create inserts a post together with its first revision.
+func (r *PostSQL) Create(post models.Post) (int64, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	insertedPost, err := tx.Exec("INSERT INTO posts(author_id, title, text, html, status, publish_at, created_at) VALUES(?, ?, ?, ?, ?, ?, ?)", post.AuthorID, post.Title, post.Text, post.HTML, post.Status, post.PublishAt, post.CreatedAt)
+	if err != nil {
+		return 0, err
+	}
+
+	postID, err := insertedPost.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := tx.Exec(insertRevisionQuery, post.CreatedAt, postID, post.AuthorID); err != nil {
+		return 0, err
+	}
+
+	return postID, tx.Commit()
+}
+
+func (r *PostSQL) FindByID(postID int64) (*models.Post, error) {
+	var post models.Post
+	err := scanPost(r.db.QueryRow("SELECT p.html, "+postColumns+" FROM posts p JOIN users u ON u.id = p.author_id WHERE p.id = ?", postID), &post, &post.HTML)
+	if err != nil {
+		return nil, err
+	}
+
+	posts := []models.Post{post}
+	if err := loadTags(r.db, posts); err != nil {
+		return nil, err
+	}
+	return &posts[0], nil
+}
+
+func (r *PostSQL) FindByAuthor(authorID int64, pagination models.Pagination) (*models.Page[models.Post], error) {
+	query, args := newKeyset(pagination, "p.id", "p.likes", "p.publish_at").apply(
+		"SELECT "+postColumns+" FROM posts p JOIN users u ON u.id = p.author_id WHERE p.author_id = ? AND p.status = ?",
+		[]interface{}{authorID, models.PostStatusPublished},
+		pagination.Limit,
+	)
+
+	return r.findPage(query, args, pagination)
+}
+
+func (r *PostSQL) FindByAuthorAndStatus(authorID int64, status models.PostStatus, pagination models.Pagination) (*models.Page[models.Post], error) {
+	query, args := newKeyset(pagination, "p.id", "p.likes", "p.created_at").apply(
+		"SELECT "+postColumns+" FROM posts p JOIN users u ON u.id = p.author_id WHERE p.author_id = ? AND p.status = ?",
+		[]interface{}{authorID, status},
+		pagination.Limit,
+	)
+
+	return r.findPage(query, args, pagination)
+}
+
+func (r *PostSQL) FindFeed(userID int64, pagination models.Pagination) (*models.Page[models.Post], error) {
+	query, args := newKeyset(pagination, "p.id", "p.likes", "p.publish_at").apply(
+		"SELECT "+postColumns+" FROM posts p JOIN followers f ON f.following_id = p.author_id JOIN users u ON u.id = p.author_id WHERE f.user_id = ? AND p.status = ?",
+		[]interface{}{userID, models.PostStatusPublished},
+		pagination.Limit,
+	)
+
+	return r.findPage(query, args, pagination)
+}
+
+func (r *PostSQL) FindByTag(tag string, pagination models.Pagination) (*models.Page[models.Post], error) {
+	query, args := newKeyset(pagination, "p.id", "p.likes", "p.publish_at").apply(
+		"SELECT "+postColumns+" FROM posts p JOIN post_tags pt ON pt.post_id = p.id JOIN tags t ON t.id = pt.tag_id JOIN users u ON u.id = p.author_id WHERE t.name = ? AND p.status = ?",
+		[]interface{}{tag, models.PostStatusPublished},
+		pagination.Limit,
+	)
+
+	return r.findPage(query, args, pagination)
+}
+
+func (r *PostSQL) FindByIDs(postIDs []int64) ([]models.Post, error) {
+	if len(postIDs) == 0 {
+		return nil, nil
+	}
+
+	args := make([]interface{}, 0, len(postIDs))
+	for _, postID := range postIDs {
+		args = append(args, postID)
+	}
+
+	rows, err := r.db.Query("SELECT "+postColumns+" FROM posts p JOIN users u ON u.id = p.author_id WHERE p.id IN ("+placeholders(len(args))+")", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var posts []models.Post
+	for rows.Next() {
+		var post models.Post
+		if err := scanPost(rows, &post); err != nil {
+			return nil, err
+		}
+		posts = append(posts, post)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := loadTags(r.db, posts); err != nil {
+		return nil, err
+	}
+	return posts, nil
+}
+
+func (r *PostSQL) findPage(query string, args []interface{}, pagination models.Pagination) (*models.Page[models.Post], error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var posts []models.Post
+	var cursors []models.Cursor
+	for rows.Next() {
+		var post models.Post
+		if err := scanPost(rows, &post); err != nil {
+			return nil, err
+		}
+		posts = append(posts, post)
+		cursors = append(cursors, postCursor(pagination, post.ID, post))
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	page := paginate(posts, cursors, pagination.Limit)
+	if err := loadTags(r.db, page.Items); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// PublishDue publishes the scheduled posts whose time has come and returns
+// their IDs. Posts published by a concurrent run are left out.
+func (r *PostSQL) PublishDue(now time.Time) ([]int64, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query("SELECT id FROM posts WHERE status = ? AND publish_at <= ?", models.PostStatusScheduled, now)
+	if err != nil {
+		return nil, err
+	}
+
+	var dueIDs []int64
+	for rows.Next() {
+		var postID int64
+		if err := rows.Scan(&postID); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		dueIDs = append(dueIDs, postID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var publishedIDs []int64
+	for _, postID := range dueIDs {
+		result, err := tx.Exec("UPDATE posts SET status = ? WHERE id = ? AND status = ?", models.PostStatusPublished, postID, models.PostStatusScheduled)
+		if err != nil {
+			return nil, err
+		}
+
+		published, err := result.RowsAffected()
+		if err != nil {
+			return nil, err
+		}
+		if published > 0 {
+			publishedIDs = append(publishedIDs, postID)
+		}
+	}
+
+	return publishedIDs, tx.Commit()
+}
+
+func (r *PostSQL) FindAuthorID(postID int64) (int64, error) {
+	var authorID int64
+	if err := r.db.QueryRow("SELECT author_id FROM posts WHERE id = ?", postID).Scan(&authorID); err != nil {
+		return 0, err
+	}
+	return authorID, nil
+}
+
+func (r *PostSQL) Exists(postID int64) (bool, error) {
+	var exists bool
+	if err := r.db.QueryRow("SELECT EXISTS(SELECT 1 FROM posts WHERE id = ?)", postID).Scan(&exists); err != nil {
+		return false, err
+	}
+	return exists, nil
+}
+
+// Update changes a post of authorID. When the title or text change, the
+// result is recorded as a new revision in the same transaction.
+func (r *PostSQL) Update(updateOpts models.PostUpdateOptions, postID int64, authorID int64, updatedAt time.Time) error {
+	updQuery, values := updateOpts.FilterUpdateOptions()
+	if updQuery == "" {
+		return nil
+	}
+
+	updQuery += ", updated_at = ? WHERE id = ? AND author_id = ?"
+	values = append(values, updatedAt, postID, authorID)
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(updQuery, values...); err != nil {
+		return err
+	}
+
+	if updateOpts.ChangesContent() {
+		if _, err := tx.Exec(insertRevisionQuery, updatedAt, postID, authorID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *PostSQL) Delete(postID int64, authorID int64) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var owned bool
+	if err := tx.QueryRow("SELECT EXISTS(SELECT 1 FROM posts WHERE id = ? AND author_id = ?)", postID, authorID).Scan(&owned); err != nil {
+		return err
+	}
+
+	// Someone else's post: leave its comments, likes and revisions untouched
+	if !owned {
+		return nil
+	}
+
+	// Comments reference the post, so they go first
+	queries := [12]string{
+		"DELETE FROM notification_actors WHERE notification_id IN (SELECT id FROM notifications WHERE post_id = ?)",
+		"DELETE FROM notifications WHERE post_id = ?",
+		"DELETE FROM bookmarks WHERE post_id = ?",
+		"DELETE FROM reactions WHERE target_type = 'comment' AND target_id IN (SELECT id FROM comments WHERE post_id = ?)",
+		"DELETE FROM reactions WHERE target_type = 'post' AND target_id = ?",
+		"DELETE FROM comment_revisions WHERE comment_id IN (SELECT id FROM comments WHERE post_id = ?)",
+		"DELETE FROM comments WHERE post_id = ?",
+		"DELETE FROM likes WHERE post_id = ?",
+		"DELETE FROM post_revisions WHERE post_id = ?",
+		"DELETE FROM post_tags WHERE post_id = ?",
+		"DELETE FROM search_index WHERE post_id = ?",
+		"DELETE FROM posts WHERE id = ?",
+	}
+
+	for _, query := range queries {
+		_, err := tx.Exec(query, postID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
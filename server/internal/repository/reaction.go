@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/morf1lo/blog-app/internal/models"
+)
+
+type ReactionSQL struct {
+	db *sql.DB
+}
+
+func NewReactionSQL(db *sql.DB) *ReactionSQL {
+	return &ReactionSQL{db: db}
+}
+
+func (r *ReactionSQL) Add(userID int64, targetType models.ReactionTarget, targetID int64, reaction string) error {
+	_, err := r.db.Exec(
+		"INSERT INTO reactions(user_id, target_type, target_id, reaction) SELECT ?, ?, ?, ? WHERE NOT EXISTS(SELECT 1 FROM reactions WHERE user_id = ? AND target_type = ? AND target_id = ? AND reaction = ?)",
+		userID, targetType, targetID, reaction, userID, targetType, targetID, reaction,
+	)
+	return err
+}
+
+func (r *ReactionSQL) Remove(userID int64, targetType models.ReactionTarget, targetID int64, reaction string) error {
+	_, err := r.db.Exec("DELETE FROM reactions WHERE user_id = ? AND target_type = ? AND target_id = ? AND reaction = ?", userID, targetType, targetID, reaction)
+	return err
+}
+
+// Tally counts the reactions on each of the given targets, marking the ones left by viewerID.
+func (r *ReactionSQL) Tally(targetType models.ReactionTarget, targetIDs []int64, viewerID int64) ([]models.ReactionTally, error) {
+	if len(targetIDs) == 0 {
+		return nil, nil
+	}
+
+	args := make([]interface{}, 0, len(targetIDs)+2)
+	args = append(args, viewerID, targetType)
+	for _, targetID := range targetIDs {
+		args = append(args, targetID)
+	}
+
+	rows, err := r.db.Query(
+		"SELECT target_id, reaction, COUNT(*), SUM(CASE WHEN user_id = ? THEN 1 ELSE 0 END) FROM reactions WHERE target_type = ? AND target_id IN ("+placeholders(len(targetIDs))+") GROUP BY target_id, reaction",
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tallies []models.ReactionTally
+	for rows.Next() {
+		var tally models.ReactionTally
+		var mine int64
+		if err := rows.Scan(&tally.TargetID, &tally.Name, &tally.Count, &mine); err != nil {
+			return nil, err
+		}
+		tally.Mine = mine > 0
+		tallies = append(tallies, tally)
+	}
+
+	return tallies, rows.Err()
+}
@@ -0,0 +1,188 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/morf1lo/blog-app/internal/models"
+)
+
+type User interface {
+	Create(user models.User, activationLink string) (int64, error)
+	FindByID(userID int64) (*models.User, error)
+	FindByUsername(username string) (*models.User, error)
+	FindCredentials(username string, email string) (*models.User, error)
+	FindPassword(userID int64) (string, error)
+	Exists(userID int64) (bool, error)
+	SetAvatar(userID int64, avatar string) error
+	Delete(userID int64) error
+}
+
+type Token interface {
+	ActivationLinkExists(activationLink string) (bool, error)
+	Activate(activationLink string) error
+	SaveResetToken(email string, token string, tokenExpiry time.Time) error
+	FindResetToken(token string) (int64, time.Time, error)
+	ClearResetToken(token string) error
+	ResetPassword(userID int64, passwordHash string) error
+}
+
+type Session interface {
+	Create(session models.Session, tokenHash string, expiresAt time.Time) (int64, error)
+	FindRefreshToken(tokenHash string) (*models.RefreshToken, error)
+	Rotate(tokenID int64, sessionID int64, newTokenHash string, usedAt time.Time, expiresAt time.Time) (bool, error)
+	IsActive(sessionID int64, userID int64) (bool, error)
+	FindActive(userID int64, now time.Time) ([]models.Session, error)
+	Touch(sessionID int64, ip string, seenAt time.Time, staleBefore time.Time) error
+	Revoke(sessionID int64, userID int64, revokedAt time.Time) (bool, error)
+	RevokeAll(userID int64, exceptSessionID int64, revokedAt time.Time) error
+}
+
+type AccessToken interface {
+	Create(token models.AccessToken, tokenHash string) (int64, error)
+	FindByHash(tokenHash string) (*models.AccessToken, error)
+	FindByUser(userID int64) ([]models.AccessToken, error)
+	Touch(tokenID int64, usedAt time.Time, staleBefore time.Time) error
+	Revoke(tokenID int64, userID int64, revokedAt time.Time) (bool, error)
+}
+
+type TwoFactor interface {
+	Find(userID int64) (*models.TwoFactor, error)
+	Enroll(twoFactor models.TwoFactor, recoveryCodeHashes []string) (bool, error)
+	Enable(userID int64, step int64, enabledAt time.Time) (bool, error)
+	UseStep(userID int64, step int64) (bool, error)
+	UseRecoveryCode(userID int64, codeHash string, usedAt time.Time) (bool, error)
+	CountRecoveryCodes(userID int64) (int64, error)
+	Disable(userID int64) error
+	CreateChallenge(userID int64, tokenHash string, createdAt time.Time, expiresAt time.Time) error
+	FindChallenge(tokenHash string) (*models.TwoFactorChallenge, error)
+	RecordFailedAttempt(challengeID int64, maxAttempts int) error
+	RecordFailedCode(userID int64, maxFailures int, lockedUntil time.Time) error
+	ResetFailedCodes(userID int64) error
+	DeleteChallenge(challengeID int64) (bool, error)
+}
+
+type Post interface {
+	Create(post models.Post) (int64, error)
+	FindByID(postID int64) (*models.Post, error)
+	FindByAuthor(authorID int64, pagination models.Pagination) (*models.Page[models.Post], error)
+	FindByAuthorAndStatus(authorID int64, status models.PostStatus, pagination models.Pagination) (*models.Page[models.Post], error)
+	FindFeed(userID int64, pagination models.Pagination) (*models.Page[models.Post], error)
+	PublishDue(now time.Time) ([]int64, error)
+	FindAuthorID(postID int64) (int64, error)
+	Exists(postID int64) (bool, error)
+	Update(updateOpts models.PostUpdateOptions, postID int64, authorID int64, updatedAt time.Time) error
+	Delete(postID int64, authorID int64) error
+	FindByIDs(postIDs []int64) ([]models.Post, error)
+	FindByTag(tag string, pagination models.Pagination) (*models.Page[models.Post], error)
+}
+
+type Tag interface {
+	SetPostTags(postID int64, tags []string) error
+	FindPopular(limit int) ([]models.TagCount, error)
+}
+
+type Revision interface {
+	FindByPost(postID int64, pagination models.Pagination) (*models.Page[models.PostRevision], error)
+	Find(postID int64, revisionNumber int64) (*models.PostRevision, error)
+}
+
+type Search interface {
+	IndexSource(postID int64, source models.SearchSource, sourceID int64, terms []string) error
+	ExpandPrefix(prefix string, limit int) ([]string, error)
+	FindMatches(query models.SearchQuery) ([]models.SearchMatch, error)
+	FindPostings(postIDs []int64, terms []string) ([]models.SearchPosting, error)
+	FindUnindexedPosts() ([]int64, error)
+	FindUnindexedComments() ([]models.Comment, error)
+	FindCommentTexts(commentIDs []int64) (map[int64]string, error)
+}
+
+type Like interface {
+	Add(userID int64, postID int64) (bool, error)
+	Remove(userID int64, postID int64) (bool, error)
+	Toggle(userID int64, postID int64) (bool, error)
+	FindLikedPosts(userID int64, pagination models.Pagination) (*models.Page[models.Post], error)
+}
+
+type Reaction interface {
+	Add(userID int64, targetType models.ReactionTarget, targetID int64, reaction string) error
+	Remove(userID int64, targetType models.ReactionTarget, targetID int64, reaction string) error
+	Tally(targetType models.ReactionTarget, targetIDs []int64, viewerID int64) ([]models.ReactionTally, error)
+}
+
+type Bookmark interface {
+	Add(userID int64, postID int64, collectionID *int64, createdAt time.Time) error
+	Remove(userID int64, postID int64) error
+	FindBookmarkedPosts(userID int64, collectionID *int64, pagination models.Pagination) (*models.Page[models.Post], error)
+	Bookmarked(userID int64, postIDs []int64) (map[int64]bool, error)
+	CreateCollection(collection models.BookmarkCollection) (int64, error)
+	FindCollection(collectionID int64) (*models.BookmarkCollection, error)
+	FindCollections(userID int64) ([]models.BookmarkCollection, error)
+	DeleteCollection(collectionID int64, userID int64) error
+}
+
+type Notification interface {
+	Record(notification models.Notification) error
+	FindByUser(userID int64, pagination models.Pagination) (*models.Page[models.Notification], error)
+	CountUnread(userID int64) (int64, error)
+	MarkRead(notificationID int64, userID int64, readAt time.Time) error
+	MarkAllRead(userID int64, readAt time.Time) error
+}
+
+type Follow interface {
+	Add(userID int64, followingID int64) (bool, error)
+	Remove(userID int64, followingID int64) (bool, error)
+	Toggle(userID int64, followingID int64) (bool, error)
+	FindFollowers(userID int64, pagination models.Pagination) (*models.Page[models.User], error)
+	FindFollows(userID int64, pagination models.Pagination) (*models.Page[models.User], error)
+}
+
+type Comment interface {
+	Create(comment models.Comment) (int64, error)
+	FindByID(commentID int64) (*models.Comment, error)
+	FindByPost(postID int64, pagination models.Pagination) (*models.Page[models.Comment], error)
+	FindReplies(parentID int64, pagination models.Pagination) (*models.Page[models.Comment], error)
+	FindFirstReplies(parentIDs []int64, limit int) (map[int64]*models.Page[models.Comment], error)
+	Update(commentID int64, text string, editedAt time.Time) error
+	FindRevisions(commentID int64, pagination models.Pagination) (*models.Page[models.CommentRevision], error)
+	FindAuthorID(commentID int64) (int64, error)
+	Delete(commentID int64, postID int64) error
+}
+
+type Repository struct {
+	User
+	Token
+	Session
+	AccessToken
+	TwoFactor
+	Post
+	Revision
+	Tag
+	Search
+	Like
+	Reaction
+	Bookmark
+	Notification
+	Follow
+	Comment
+}
+
+func NewRepository(db *sql.DB) *Repository {
+	return &Repository{
+		User: NewUserSQL(db),
+		Token: NewTokenSQL(db),
+		Session: NewSessionSQL(db),
+		AccessToken: NewAccessTokenSQL(db),
+		TwoFactor: NewTwoFactorSQL(db),
+		Post: NewPostSQL(db),
+		Revision: NewRevisionSQL(db),
+		Tag: NewTagSQL(db),
+		Search: NewSearchSQL(db),
+		Like: NewLikeSQL(db),
+		Reaction: NewReactionSQL(db),
+		Bookmark: NewBookmarkSQL(db),
+		Notification: NewNotificationSQL(db),
+		Follow: NewFollowSQL(db),
+		Comment: NewCommentSQL(db),
+	}
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/morf1lo/blog-app/internal/config"
+	"github.com/morf1lo/blog-app/internal/db"
+	"github.com/morf1lo/blog-app/internal/migrate"
+	"github.com/morf1lo/blog-app/internal/models"
+)
+
+// newTestDB opens a migrated SQLite database in a temporary file, with the
+// same connection settings as the server.
+func newTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	conn, err := db.Connect(config.DBConfig{Driver: db.DriverSQLite, Path: filepath.Join(t.TempDir(), "test.db")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	migrator, err := migrate.NewMigrator(conn, db.DriverSQLite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrator.Up(); err != nil {
+		t.Fatal(err)
+	}
+
+	return conn
+}
+
+func createTestUser(t *testing.T, conn *sql.DB, username string) int64 {
+	t.Helper()
+
+	userID, err := NewUserSQL(conn).Create(models.User{Username: username, Email: username + "@example.com", Password: "hash"}, username)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return userID
+}
+
+func createTestPost(t *testing.T, conn *sql.DB, authorID int64) int64 {
+	t.Helper()
+
+	publishAt := time.Now().UTC().Truncate(time.Second)
+	postID, err := NewPostSQL(conn).Create(models.Post{
+		AuthorID: authorID,
+		Title: "Post",
+		Text: "Text",
+		Status: models.PostStatusPublished,
+		PublishAt: &publishAt,
+		CreatedAt: publishAt,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return postID
+}
+
+func queryInt(t *testing.T, conn *sql.DB, query string, args ...interface{}) int64 {
+	t.Helper()
+
+	var value int64
+	if err := conn.QueryRow(query, args...).Scan(&value); err != nil {
+		t.Fatal(fmt.Errorf("%s: %w", query, err))
+	}
+	return value
+}
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/morf1lo/blog-app/internal/models"
+)
+
+type RevisionSQL struct {
+	db *sql.DB
+}
+
+func NewRevisionSQL(db *sql.DB) *RevisionSQL {
+	return &RevisionSQL{db: db}
+}
+
+func (r *RevisionSQL) FindByPost(postID int64, pagination models.Pagination) (*models.Page[models.PostRevision], error) {
+	query, args := newKeyset(pagination, "revision", "", "").apply(
+		"SELECT id, post_id, revision, title, text, created_at FROM post_revisions WHERE post_id = ?",
+		[]interface{}{postID},
+		pagination.Limit,
+	)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var revisions []models.PostRevision
+	var cursors []models.Cursor
+	for rows.Next() {
+		var revision models.PostRevision
+		if err := rows.Scan(&revision.ID, &revision.PostID, &revision.Revision, &revision.Title, &revision.Text, &revision.CreatedAt); err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, revision)
+		cursors = append(cursors, models.Cursor{Sort: pagination.Sort, ID: revision.Revision})
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return paginate(revisions, cursors, pagination.Limit), nil
+}
+
+func (r *RevisionSQL) Find(postID int64, revisionNumber int64) (*models.PostRevision, error) {
+	var revision models.PostRevision
+	err := r.db.QueryRow("SELECT id, post_id, revision, title, text, created_at FROM post_revisions WHERE post_id = ? AND revision = ?", postID, revisionNumber).Scan(&revision.ID, &revision.PostID, &revision.Revision, &revision.Title, &revision.Text, &revision.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &revision, nil
+}
@@ -0,0 +1,328 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/morf1lo/blog-app/internal/models"
+)
+
+// Rows per INSERT when writing the index, well below the placeholder limits of both drivers
+const searchInsertBatch = 500
+
+type SearchSQL struct {
+	db *sql.DB
+}
+
+func NewSearchSQL(db *sql.DB) *SearchSQL {
+	return &SearchSQL{db: db}
+}
+
+// IndexSource replaces the indexed terms of one source of a post (its title,
+// text, author or one of its comments). Terms are stored with their position
+// so that phrases can be matched.
+func (r *SearchSQL) IndexSource(postID int64, source models.SearchSource, sourceID int64, terms []string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM search_index WHERE source = ? AND source_id = ?", source, sourceID); err != nil {
+		return err
+	}
+
+	for start := 0; start < len(terms); start += searchInsertBatch {
+		end := start + searchInsertBatch
+		if end > len(terms) {
+			end = len(terms)
+		}
+
+		query := "INSERT INTO search_index(post_id, source, source_id, term, position) VALUES"
+		args := make([]interface{}, 0, (end-start)*5)
+		for position := start; position < end; position++ {
+			if position > start {
+				query += ","
+			}
+			query += " (?, ?, ?, ?, ?)"
+			args = append(args, postID, source, sourceID, terms[position], position)
+		}
+
+		if _, err := tx.Exec(query, args...); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// ExpandPrefix returns up to limit distinct indexed terms starting with prefix.
+// The lower bound lets the term index be used even where LIKE cannot.
+func (r *SearchSQL) ExpandPrefix(prefix string, limit int) ([]string, error) {
+	rows, err := r.db.Query("SELECT DISTINCT term FROM search_index WHERE term >= ? AND term LIKE ? ESCAPE '!' ORDER BY term LIMIT ?", prefix, escapeLike(prefix)+"%", limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var terms []string
+	for rows.Next() {
+		var term string
+		if err := rows.Scan(&term); err != nil {
+			return nil, err
+		}
+		terms = append(terms, term)
+	}
+
+	return terms, rows.Err()
+}
+
+// escapeLike escapes the LIKE wildcards in s, for use with ESCAPE '!'.
+// A backslash is avoided as the escape character since MySQL treats it as
+// one inside string literals too.
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+var likeEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+
+// Matches in titles count more than matches in the author or body, which count more than comments
+var searchSourceWeights = []struct {
+	source models.SearchSource
+	weight int
+}{
+	{models.SearchSourceTitle, 4},
+	{models.SearchSourceAuthor, 3},
+	{models.SearchSourceText, 2},
+	{models.SearchSourceComment, 1},
+}
+
+// termSaturation is how quickly repeating a term stops raising the score, as k1 in BM25.
+const termSaturation = 1.2
+
+// FindMatches returns one page of the published posts matching every clause
+// of the query. Posts are scored with a BM25 style sum: each clause adds its
+// inverse document frequency scaled by the saturated, source weighted count
+// of its occurrences in the post.
+func (r *SearchSQL) FindMatches(query models.SearchQuery) ([]models.SearchMatch, error) {
+	totalPosts, err := r.CountPosts()
+	if err != nil {
+		return nil, err
+	}
+
+	var joins, score string
+	var args []interface{}
+	for i, clause := range query.Clauses {
+		subquery, subqueryArgs := clauseMatches(clause)
+
+		var documentFrequency int64
+		countArgs := append(append([]interface{}{}, subqueryArgs...), models.PostStatusPublished)
+		if err := r.db.QueryRow("SELECT COUNT(*) FROM ("+subquery+") c JOIN posts p ON p.id = c.post_id WHERE p.status = ?", countArgs...).Scan(&documentFrequency); err != nil {
+			return nil, err
+		}
+		if documentFrequency == 0 {
+			return []models.SearchMatch{}, nil
+		}
+
+		alias := "c" + strconv.Itoa(i)
+		joins += " JOIN (" + subquery + ") " + alias + " ON " + alias + ".post_id = p.id"
+		args = append(args, subqueryArgs...)
+
+		idf := math.Log(1 + float64(totalPosts)/float64(documentFrequency))
+		if score != "" {
+			score += " + "
+		}
+		score += fmt.Sprintf("%s.frequency * %g / (%s.frequency + %g) * %g", alias, termSaturation+1, alias, termSaturation, idf)
+	}
+
+	sqlQuery := "SELECT p.id, " + score + " AS score FROM posts p" + joins + " WHERE p.status = ?"
+	args = append(args, models.PostStatusPublished)
+
+	if len(query.Tags) > 0 {
+		filter, filterArgs := tagFilter("p.id", query.Tags)
+		sqlQuery += " AND " + filter
+		args = append(args, filterArgs...)
+	}
+
+	switch query.Sort {
+	case models.SortNewest:
+		sqlQuery += " ORDER BY p.publish_at DESC, p.id DESC"
+	case models.SortOldest:
+		sqlQuery += " ORDER BY p.publish_at ASC, p.id ASC"
+	case models.SortMostLiked:
+		sqlQuery += " ORDER BY p.likes DESC, p.id DESC"
+	default:
+		sqlQuery += " ORDER BY score DESC, p.id DESC"
+	}
+	sqlQuery += " LIMIT ? OFFSET ?"
+	args = append(args, query.Limit, query.Offset)
+
+	rows, err := r.db.Query(sqlQuery, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	matches := []models.SearchMatch{}
+	for rows.Next() {
+		var match models.SearchMatch
+		if err := rows.Scan(&match.PostID, &match.Score); err != nil {
+			return nil, err
+		}
+		matches = append(matches, match)
+	}
+
+	return matches, rows.Err()
+}
+
+// clauseMatches returns a query listing the posts a clause matches, each with
+// the weighted number of times it occurs in them. Phrases are matched by
+// joining the occurrences of their terms on position, starting from the
+// longest term since it is likely the rarest.
+func clauseMatches(clause models.SearchClause) (string, []interface{}) {
+	weight := "CASE s0.source"
+	for _, sourceWeight := range searchSourceWeights {
+		weight += fmt.Sprintf(" WHEN '%s' THEN %d", sourceWeight.source, sourceWeight.weight)
+	}
+	weight += " ELSE 0 END"
+
+	if !clause.Phrase {
+		args := make([]interface{}, 0, len(clause.Terms))
+		for _, term := range clause.Terms {
+			args = append(args, term)
+		}
+		return "SELECT s0.post_id, SUM(" + weight + ") AS frequency FROM search_index s0 WHERE s0.term IN (" + placeholders(len(args)) + ") GROUP BY s0.post_id", args
+	}
+
+	anchor := 0
+	for i, term := range clause.Terms {
+		if len(term) > len(clause.Terms[anchor]) {
+			anchor = i
+		}
+	}
+
+	query := "SELECT s0.post_id, SUM(" + weight + ") AS frequency FROM search_index s0"
+	args := make([]interface{}, 0, len(clause.Terms))
+	for i, term := range clause.Terms {
+		if i == anchor {
+			continue
+		}
+		alias := "s" + strconv.Itoa(i+1)
+		query += fmt.Sprintf(" JOIN search_index %s ON %s.source = s0.source AND %s.source_id = s0.source_id AND %s.position = s0.position + %d AND %s.term = ?", alias, alias, alias, alias, i-anchor, alias)
+		args = append(args, term)
+	}
+	query += " WHERE s0.term = ? GROUP BY s0.post_id"
+	args = append(args, clause.Terms[anchor])
+
+	return query, args
+}
+
+// FindPostings returns the occurrences of terms in the given posts.
+func (r *SearchSQL) FindPostings(postIDs []int64, terms []string) ([]models.SearchPosting, error) {
+	if len(postIDs) == 0 || len(terms) == 0 {
+		return nil, nil
+	}
+
+	args := make([]interface{}, 0, len(postIDs)+len(terms))
+	for _, postID := range postIDs {
+		args = append(args, postID)
+	}
+	for _, term := range terms {
+		args = append(args, term)
+	}
+
+	rows, err := r.db.Query("SELECT post_id, source, source_id, term, position FROM search_index WHERE post_id IN ("+placeholders(len(postIDs))+") AND term IN ("+placeholders(len(terms))+")", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var postings []models.SearchPosting
+	for rows.Next() {
+		var posting models.SearchPosting
+		if err := rows.Scan(&posting.PostID, &posting.Source, &posting.SourceID, &posting.Term, &posting.Position); err != nil {
+			return nil, err
+		}
+		postings = append(postings, posting)
+	}
+
+	return postings, rows.Err()
+}
+
+func (r *SearchSQL) CountPosts() (int64, error) {
+	var count int64
+	if err := r.db.QueryRow("SELECT COUNT(*) FROM posts WHERE status = ?", models.PostStatusPublished).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *SearchSQL) FindUnindexedPosts() ([]int64, error) {
+	rows, err := r.db.Query("SELECT id FROM posts WHERE id NOT IN (SELECT source_id FROM search_index WHERE source = ?)", models.SearchSourceTitle)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var postIDs []int64
+	for rows.Next() {
+		var postID int64
+		if err := rows.Scan(&postID); err != nil {
+			return nil, err
+		}
+		postIDs = append(postIDs, postID)
+	}
+
+	return postIDs, rows.Err()
+}
+
+func (r *SearchSQL) FindUnindexedComments() ([]models.Comment, error) {
+	rows, err := r.db.Query("SELECT id, post_id, text FROM comments WHERE deleted = FALSE AND id NOT IN (SELECT source_id FROM search_index WHERE source = ?)", models.SearchSourceComment)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var comments []models.Comment
+	for rows.Next() {
+		var comment models.Comment
+		if err := rows.Scan(&comment.ID, &comment.Post.ID, &comment.Text); err != nil {
+			return nil, err
+		}
+		comments = append(comments, comment)
+	}
+
+	return comments, rows.Err()
+}
+
+func (r *SearchSQL) FindCommentTexts(commentIDs []int64) (map[int64]string, error) {
+	texts := make(map[int64]string, len(commentIDs))
+	if len(commentIDs) == 0 {
+		return texts, nil
+	}
+
+	args := make([]interface{}, 0, len(commentIDs))
+	for _, commentID := range commentIDs {
+		args = append(args, commentID)
+	}
+
+	rows, err := r.db.Query("SELECT id, text FROM comments WHERE id IN ("+placeholders(len(args))+")", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var commentID int64
+		var text string
+		if err := rows.Scan(&commentID, &text); err != nil {
+			return nil, err
+		}
+		texts[commentID] = text
+	}
+
+	return texts, rows.Err()
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/morf1lo/blog-app/internal/models"
+)
+
+func TestExpandPrefixEscapesWildcards(t *testing.T) {
+	conn := newTestDB(t)
+	search := NewSearchSQL(conn)
+
+	postID := createTestPost(t, conn, createTestUser(t, conn, "alice"))
+	if err := search.IndexSource(postID, models.SearchSourceText, postID, []string{"a_bc", "axbc", "a%z", "ab!c", "abd"}); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string][]string{
+		"a_": {"a_bc"},
+		"a%": {"a%z"},
+		"ab!": {"ab!c"},
+		"ab": {"ab!c", "abd"},
+	}
+	for prefix, want := range cases {
+		terms, err := search.ExpandPrefix(prefix, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(terms, want) {
+			t.Errorf("ExpandPrefix(%q) = %v, want %v", prefix, terms, want)
+		}
+	}
+
+	terms, err := search.ExpandPrefix("a", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(terms) != 2 {
+		t.Errorf("ExpandPrefix with limit 2 returned %v", terms)
+	}
+}
+
+func TestFindMatchesRequiresEveryClause(t *testing.T) {
+	conn := newTestDB(t)
+	search := NewSearchSQL(conn)
+
+	authorID := createTestUser(t, conn, "alice")
+	for _, text := range [][]string{
+		{"quick", "brown", "fox"},
+		{"brown", "quick", "fox", "fox"},
+		{"slow", "brown", "dog"},
+	} {
+		postID := createTestPost(t, conn, authorID)
+		if err := search.IndexSource(postID, models.SearchSourceText, postID, text); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	matchIDs := func(query models.SearchQuery) []int64 {
+		t.Helper()
+		query.Limit = 10
+		matches, err := search.FindMatches(query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids := []int64{}
+		for _, match := range matches {
+			ids = append(ids, match.PostID)
+		}
+		return ids
+	}
+
+	if ids := matchIDs(models.SearchQuery{Clauses: []models.SearchClause{{Terms: []string{"quick", "brown"}, Phrase: true}}}); !reflect.DeepEqual(ids, []int64{1}) {
+		t.Errorf("phrase matched %v, want [1]", ids)
+	}
+	if ids := matchIDs(models.SearchQuery{Clauses: []models.SearchClause{{Terms: []string{"fox"}}, {Terms: []string{"brown"}}}}); !reflect.DeepEqual(ids, []int64{2, 1}) {
+		t.Errorf("terms matched %v, want [2 1]", ids)
+	}
+	if ids := matchIDs(models.SearchQuery{Clauses: []models.SearchClause{{Terms: []string{"fox"}}, {Terms: []string{"cat"}}}}); len(ids) != 0 {
+		t.Errorf("unknown term matched %v", ids)
+	}
+	if ids := matchIDs(models.SearchQuery{Clauses: []models.SearchClause{{Terms: []string{"brown"}}}, Sort: models.SortOldest, Offset: 1}); !reflect.DeepEqual(ids, []int64{2, 3}) {
+		t.Errorf("second page matched %v, want [2 3]", ids)
+	}
+}
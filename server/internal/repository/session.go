@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/morf1lo/blog-app/internal/models"
+)
+
+type SessionSQL struct {
+	db *sql.DB
+}
+
+func NewSessionSQL(db *sql.DB) *SessionSQL {
+	return &SessionSQL{db: db}
+}
+
+// Create starts a session together with its first refresh token.
+func (r *SessionSQL) Create(session models.Session, tokenHash string, expiresAt time.Time) (int64, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec("INSERT INTO sessions(user_id, user_agent, ip, created_at, last_seen_at) VALUES(?, ?, ?, ?, ?)", session.UserID, session.UserAgent, session.IP, session.CreatedAt, session.CreatedAt)
+	if err != nil {
+		return 0, err
+	}
+
+	sessionID, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = tx.Exec("INSERT INTO refresh_tokens(session_id, token_hash, created_at, expires_at) VALUES(?, ?, ?, ?)", sessionID, tokenHash, session.CreatedAt, expiresAt)
+	if err != nil {
+		return 0, err
+	}
+
+	return sessionID, tx.Commit()
+}
+
+func (r *SessionSQL) FindRefreshToken(tokenHash string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	err := r.db.QueryRow(
+		"SELECT t.id, t.session_id, s.user_id, t.expires_at, t.used_at, s.revoked_at IS NOT NULL FROM refresh_tokens t JOIN sessions s ON s.id = t.session_id WHERE t.token_hash = ?",
+		tokenHash,
+	).Scan(&token.ID, &token.SessionID, &token.UserID, &token.ExpiresAt, &token.UsedAt, &token.Revoked)
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// Rotate marks a refresh token as used and issues its successor. It reports
+// false when the token was already used, e.g. by a concurrent request.
+func (r *SessionSQL) Rotate(tokenID int64, sessionID int64, newTokenHash string, usedAt time.Time, expiresAt time.Time) (bool, error) {
+	return inTx(r.db, func(tx *sql.Tx) (bool, error) {
+		result, err := tx.Exec("UPDATE refresh_tokens SET used_at = ? WHERE id = ? AND used_at IS NULL", usedAt, tokenID)
+		if err != nil {
+			return false, err
+		}
+
+		rotated, err := result.RowsAffected()
+		if err != nil || rotated == 0 {
+			return false, err
+		}
+
+		_, err = tx.Exec("INSERT INTO refresh_tokens(session_id, token_hash, created_at, expires_at) VALUES(?, ?, ?, ?)", sessionID, newTokenHash, usedAt, expiresAt)
+		return err == nil, err
+	})
+}
+
+func (r *SessionSQL) IsActive(sessionID int64, userID int64) (bool, error) {
+	var active bool
+	if err := r.db.QueryRow("SELECT EXISTS(SELECT 1 FROM sessions WHERE id = ? AND user_id = ? AND revoked_at IS NULL)", sessionID, userID).Scan(&active); err != nil {
+		return false, err
+	}
+	return active, nil
+}
+
+// FindActive returns the sessions of a user that are neither revoked nor
+// expired, most recently used first.
+func (r *SessionSQL) FindActive(userID int64, now time.Time) ([]models.Session, error) {
+	rows, err := r.db.Query(
+		"SELECT s.id, s.user_id, s.user_agent, s.ip, s.created_at, s.last_seen_at FROM sessions s WHERE s.user_id = ? AND s.revoked_at IS NULL AND EXISTS(SELECT 1 FROM refresh_tokens t WHERE t.session_id = s.id AND t.used_at IS NULL AND t.expires_at > ?) ORDER BY COALESCE(s.last_seen_at, s.created_at) DESC, s.id DESC",
+		userID, now,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []models.Session{}
+	for rows.Next() {
+		var session models.Session
+		if err := rows.Scan(&session.ID, &session.UserID, &session.UserAgent, &session.IP, &session.CreatedAt, &session.LastSeenAt); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+
+	return sessions, rows.Err()
+}
+
+// Touch records that a session was used from ip, at most once per staleBefore
+// interval so that every request does not write to the database.
+func (r *SessionSQL) Touch(sessionID int64, ip string, seenAt time.Time, staleBefore time.Time) error {
+	_, err := r.db.Exec("UPDATE sessions SET last_seen_at = ?, ip = ? WHERE id = ? AND (last_seen_at IS NULL OR last_seen_at < ? OR ip <> ?)", seenAt, ip, sessionID, staleBefore, ip)
+	return err
+}
+
+// Revoke revokes an active session of a user, reporting false when there is none with that ID.
+func (r *SessionSQL) Revoke(sessionID int64, userID int64, revokedAt time.Time) (bool, error) {
+	result, err := r.db.Exec("UPDATE sessions SET revoked_at = ? WHERE id = ? AND user_id = ? AND revoked_at IS NULL", revokedAt, sessionID, userID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// RevokeAll revokes every session of a user except exceptSessionID; pass 0 to revoke them all.
+func (r *SessionSQL) RevokeAll(userID int64, exceptSessionID int64, revokedAt time.Time) error {
+	_, err := r.db.Exec("UPDATE sessions SET revoked_at = ? WHERE user_id = ? AND id <> ? AND revoked_at IS NULL", revokedAt, userID, exceptSessionID)
+	return err
+}
@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"database/sql"
+	"strings"
+
+	"github.com/morf1lo/blog-app/internal/models"
+)
+
+type TagSQL struct {
+	db *sql.DB
+}
+
+func NewTagSQL(db *sql.DB) *TagSQL {
+	return &TagSQL{db: db}
+}
+
+// SetPostTags replaces the tags of a post, creating tags that do not exist yet.
+func (r *TagSQL) SetPostTags(postID int64, tags []string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM post_tags WHERE post_id = ?", postID); err != nil {
+		return err
+	}
+
+	for _, tag := range tags {
+		var tagID int64
+		err := tx.QueryRow("SELECT id FROM tags WHERE name = ?", tag).Scan(&tagID)
+		if err == sql.ErrNoRows {
+			insertedTag, err := tx.Exec("INSERT INTO tags(name) VALUES(?)", tag)
+			if err != nil {
+				return err
+			}
+			tagID, err = insertedTag.LastInsertId()
+			if err != nil {
+				return err
+			}
+		} else if err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec("INSERT INTO post_tags(post_id, tag_id) VALUES(?, ?)", postID, tagID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *TagSQL) FindPopular(limit int) ([]models.TagCount, error) {
+	rows, err := r.db.Query(
+		"SELECT t.name, COUNT(*) AS posts FROM tags t JOIN post_tags pt ON pt.tag_id = t.id JOIN posts p ON p.id = pt.post_id WHERE p.status = ? GROUP BY t.id, t.name ORDER BY posts DESC, t.name LIMIT ?",
+		models.PostStatusPublished, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := []models.TagCount{}
+	for rows.Next() {
+		var tag models.TagCount
+		if err := rows.Scan(&tag.Name, &tag.Posts); err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+
+	return tags, rows.Err()
+}
+
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+// tagFilter returns a condition matching posts that carry every one of tags.
+func tagFilter(idColumn string, tags []string) (string, []interface{}) {
+	args := make([]interface{}, 0, len(tags)+1)
+	for _, tag := range tags {
+		args = append(args, tag)
+	}
+	args = append(args, len(tags))
+
+	return idColumn + " IN (SELECT pt.post_id FROM post_tags pt JOIN tags t ON t.id = pt.tag_id WHERE t.name IN (" + placeholders(len(tags)) + ") GROUP BY pt.post_id HAVING COUNT(*) = ?)", args
+}
+
+// loadTags fills in the tags of already fetched posts with a single query.
+func loadTags(db *sql.DB, posts []models.Post) error {
+	if len(posts) == 0 {
+		return nil
+	}
+
+	byID := make(map[int64]*models.Post, len(posts))
+	args := make([]interface{}, 0, len(posts))
+	for i := range posts {
+		posts[i].Tags = []string{}
+		byID[posts[i].ID] = &posts[i]
+		args = append(args, posts[i].ID)
+	}
+
+	rows, err := db.Query("SELECT pt.post_id, t.name FROM post_tags pt JOIN tags t ON t.id = pt.tag_id WHERE pt.post_id IN ("+placeholders(len(args))+") ORDER BY t.name", args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var postID int64
+		var name string
+		if err := rows.Scan(&postID, &name); err != nil {
+			return err
+		}
+		post := byID[postID]
+		post.Tags = append(post.Tags, name)
+	}
+
+	return rows.Err()
+}
@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+)
+
+type TokenSQL struct {
+	db *sql.DB
+}
+
+func NewTokenSQL(db *sql.DB) *TokenSQL {
+	return &TokenSQL{db: db}
+}
+
+func (r *TokenSQL) ActivationLinkExists(activationLink string) (bool, error) {
+	var exists bool
+	if err := r.db.QueryRow("SELECT EXISTS(SELECT 1 FROM users WHERE activation_link = ?)", activationLink).Scan(&exists); err != nil {
+		return false, err
+	}
+	return exists, nil
+}
+
+func (r *TokenSQL) Activate(activationLink string) error {
+	_, err := r.db.Exec("UPDATE users SET activated = true, activation_link = null WHERE activation_link = ?", activationLink)
+	return err
+}
+
+func (r *TokenSQL) SaveResetToken(email string, token string, tokenExpiry time.Time) error {
+	_, err := r.db.Exec("UPDATE users SET reset_token = ?, reset_token_expiry = ? WHERE email = ?", token, tokenExpiry, email)
+	return err
+}
+
+func (r *TokenSQL) FindResetToken(token string) (int64, time.Time, error) {
+	var userID int64
+	var tokenExpiry time.Time
+	if err := r.db.QueryRow("SELECT id, reset_token_expiry FROM users WHERE reset_token = ?", token).Scan(&userID, &tokenExpiry); err != nil {
+		return 0, time.Time{}, err
+	}
+
+	return userID, tokenExpiry, nil
+}
+
+func (r *TokenSQL) ClearResetToken(token string) error {
+	_, err := r.db.Exec("UPDATE users SET reset_token = null, reset_token_expiry = null WHERE reset_token = ?", token)
+	return err
+}
+
+func (r *TokenSQL) ResetPassword(userID int64, passwordHash string) error {
+	_, err := r.db.Exec("UPDATE users SET password = ?, reset_token = null, reset_token_expiry = null WHERE id = ?", passwordHash, userID)
+	return err
+}
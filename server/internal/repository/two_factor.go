@@ -0,0 +1,210 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/morf1lo/blog-app/internal/models"
+)
+
+type TwoFactorSQL struct {
+	db *sql.DB
+}
+
+func NewTwoFactorSQL(db *sql.DB) *TwoFactorSQL {
+	return &TwoFactorSQL{db: db}
+}
+
+func (r *TwoFactorSQL) Find(userID int64) (*models.TwoFactor, error) {
+	var twoFactor models.TwoFactor
+	err := r.db.QueryRow("SELECT user_id, secret, last_step, failed_attempts, locked_until, created_at, enabled_at FROM two_factor WHERE user_id = ?", userID).Scan(&twoFactor.UserID, &twoFactor.Secret, &twoFactor.LastStep, &twoFactor.FailedAttempts, &twoFactor.LockedUntil, &twoFactor.CreatedAt, &twoFactor.EnabledAt)
+	if err != nil {
+		return nil, err
+	}
+	return &twoFactor, nil
+}
+
+// Enroll replaces a pending enrollment, and its recovery codes, with a new one.
+// It reports false when two-factor authentication is already enabled.
+func (r *TwoFactorSQL) Enroll(twoFactor models.TwoFactor, recoveryCodeHashes []string) (bool, error) {
+	return inTx(r.db, func(tx *sql.Tx) (bool, error) {
+		var enabled bool
+		err := tx.QueryRow("SELECT EXISTS(SELECT 1 FROM two_factor WHERE user_id = ? AND enabled_at IS NOT NULL)", twoFactor.UserID).Scan(&enabled)
+		if err != nil || enabled {
+			return false, err
+		}
+
+		queries := [2]string{
+			"DELETE FROM two_factor WHERE user_id = ?",
+			"DELETE FROM recovery_codes WHERE user_id = ?",
+		}
+		for _, query := range queries {
+			if _, err := tx.Exec(query, twoFactor.UserID); err != nil {
+				return false, err
+			}
+		}
+
+		_, err = tx.Exec("INSERT INTO two_factor(user_id, secret, created_at) VALUES(?, ?, ?)", twoFactor.UserID, twoFactor.Secret, twoFactor.CreatedAt)
+		if err != nil {
+			return false, err
+		}
+
+		for _, hash := range recoveryCodeHashes {
+			if _, err := tx.Exec("INSERT INTO recovery_codes(user_id, code_hash) VALUES(?, ?)", twoFactor.UserID, hash); err != nil {
+				return false, err
+			}
+		}
+
+		return true, nil
+	})
+}
+
+// Enable turns on a pending enrollment, recording the step of the code that
+// confirmed it. It reports false when there is nothing to enable.
+func (r *TwoFactorSQL) Enable(userID int64, step int64, enabledAt time.Time) (bool, error) {
+	result, err := r.db.Exec("UPDATE two_factor SET enabled_at = ?, last_step = ? WHERE user_id = ? AND enabled_at IS NULL", enabledAt, step, userID)
+	if err != nil {
+		return false, err
+	}
+
+	enabled, err := result.RowsAffected()
+	return enabled > 0, err
+}
+
+// UseStep records that the code of a time step was used. It reports false when
+// a code of that step or a later one was already used, so codes cannot be replayed.
+func (r *TwoFactorSQL) UseStep(userID int64, step int64) (bool, error) {
+	result, err := r.db.Exec("UPDATE two_factor SET last_step = ? WHERE user_id = ? AND last_step < ?", step, userID, step)
+	if err != nil {
+		return false, err
+	}
+
+	used, err := result.RowsAffected()
+	return used > 0, err
+}
+
+// UseRecoveryCode reports false when the user has no such unused code.
+func (r *TwoFactorSQL) UseRecoveryCode(userID int64, codeHash string, usedAt time.Time) (bool, error) {
+	result, err := r.db.Exec("UPDATE recovery_codes SET used_at = ? WHERE user_id = ? AND code_hash = ? AND used_at IS NULL", usedAt, userID, codeHash)
+	if err != nil {
+		return false, err
+	}
+
+	used, err := result.RowsAffected()
+	return used > 0, err
+}
+
+func (r *TwoFactorSQL) CountRecoveryCodes(userID int64) (int64, error) {
+	var count int64
+	if err := r.db.QueryRow("SELECT COUNT(*) FROM recovery_codes WHERE user_id = ? AND used_at IS NULL", userID).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *TwoFactorSQL) Disable(userID int64) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	queries := [3]string{
+		"DELETE FROM two_factor_challenges WHERE user_id = ?",
+		"DELETE FROM recovery_codes WHERE user_id = ?",
+		"DELETE FROM two_factor WHERE user_id = ?",
+	}
+
+	for _, query := range queries {
+		if _, err := tx.Exec(query, userID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// CreateChallenge also clears the user's expired challenges.
+func (r *TwoFactorSQL) CreateChallenge(userID int64, tokenHash string, createdAt time.Time, expiresAt time.Time) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM two_factor_challenges WHERE user_id = ? AND expires_at <= ?", userID, createdAt); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("INSERT INTO two_factor_challenges(user_id, token_hash, expires_at) VALUES(?, ?, ?)", userID, tokenHash, expiresAt); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *TwoFactorSQL) FindChallenge(tokenHash string) (*models.TwoFactorChallenge, error) {
+	var challenge models.TwoFactorChallenge
+	err := r.db.QueryRow("SELECT id, user_id, attempts, expires_at FROM two_factor_challenges WHERE token_hash = ?", tokenHash).Scan(&challenge.ID, &challenge.UserID, &challenge.Attempts, &challenge.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	return &challenge, nil
+}
+
+// RecordFailedAttempt counts a wrong code against a challenge, deleting the
+// challenge once maxAttempts is reached.
+func (r *TwoFactorSQL) RecordFailedAttempt(challengeID int64, maxAttempts int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("UPDATE two_factor_challenges SET attempts = attempts + 1 WHERE id = ?", challengeID); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("DELETE FROM two_factor_challenges WHERE id = ? AND attempts >= ?", challengeID, maxAttempts); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// RecordFailedCode counts a wrong code against the user, whichever challenge
+// it was sent for. Once maxFailures is reached sign-in is locked until
+// lockedUntil and counting starts over.
+func (r *TwoFactorSQL) RecordFailedCode(userID int64, maxFailures int, lockedUntil time.Time) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("UPDATE two_factor SET failed_attempts = failed_attempts + 1 WHERE user_id = ?", userID); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("UPDATE two_factor SET failed_attempts = 0, locked_until = ? WHERE user_id = ? AND failed_attempts >= ?", lockedUntil, userID, maxFailures); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *TwoFactorSQL) ResetFailedCodes(userID int64) error {
+	_, err := r.db.Exec("UPDATE two_factor SET failed_attempts = 0 WHERE user_id = ?", userID)
+	return err
+}
+
+// DeleteChallenge reports false when the challenge was already used.
+func (r *TwoFactorSQL) DeleteChallenge(challengeID int64) (bool, error) {
+	result, err := r.db.Exec("DELETE FROM two_factor_challenges WHERE id = ?", challengeID)
+	if err != nil {
+		return false, err
+	}
+
+	deleted, err := result.RowsAffected()
+	return deleted > 0, err
+}
@@ -0,0 +1,36 @@
+package repository
+
+import "database/sql"
+
+// maxTxAttempts is how many times inTx runs a transaction that keeps losing deadlocks.
+const maxTxAttempts = 3
+
+// inTx runs fn in a transaction that is committed only if fn succeeds. A
+// transaction rolled back to break a deadlock is run again from the start,
+// so fn must not have effects outside of tx.
+func inTx(db *sql.DB, fn func(tx *sql.Tx) (bool, error)) (bool, error) {
+	var result bool
+	var err error
+	for attempt := 0; attempt < maxTxAttempts; attempt++ {
+		result, err = runTx(db, fn)
+		if !isDeadlock(err) {
+			break
+		}
+	}
+	return result, err
+}
+
+func runTx(db *sql.DB, fn func(tx *sql.Tx) (bool, error)) (bool, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	result, err := fn(tx)
+	if err != nil {
+		return false, err
+	}
+
+	return result, tx.Commit()
+}
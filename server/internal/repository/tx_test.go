@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+func TestInTxRetriesDeadlocks(t *testing.T) {
+	conn := newTestDB(t)
+	userID := createTestUser(t, conn, "liker")
+	postID := createTestPost(t, conn, createTestUser(t, conn, "author"))
+	deadlock := &mysql.MySQLError{Number: mysqlDeadlock, Message: "Deadlock found when trying to get lock"}
+
+	// The like of a deadlocked attempt is rolled back, the next attempt commits
+	attempts := 0
+	added, err := inTx(conn, func(tx *sql.Tx) (bool, error) {
+		attempts++
+		added, err := addLike(tx, userID, postID)
+		if attempts == 1 {
+			return false, deadlock
+		}
+		return added, err
+	})
+	if err != nil || !added || attempts != 2 {
+		t.Fatalf("inTx = %v, %v after %d attempts, want true, nil after 2", added, err, attempts)
+	}
+	if likes := queryInt(t, conn, "SELECT likes FROM posts WHERE id = ?", postID); likes != 1 {
+		t.Errorf("posts.likes = %d, want 1", likes)
+	}
+
+	attempts = 0
+	if _, err := inTx(conn, func(tx *sql.Tx) (bool, error) {
+		attempts++
+		return false, deadlock
+	}); !errors.Is(err, deadlock) || attempts != maxTxAttempts {
+		t.Errorf("endless deadlocks: got %v after %d attempts, want the deadlock after %d", err, attempts, maxTxAttempts)
+	}
+
+	// Other errors are returned at once
+	attempts = 0
+	other := errors.New("other")
+	if _, err := inTx(conn, func(tx *sql.Tx) (bool, error) {
+		attempts++
+		return false, other
+	}); !errors.Is(err, other) || attempts != 1 {
+		t.Errorf("other error: got %v after %d attempts, want it after 1", err, attempts)
+	}
+}
@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/morf1lo/blog-app/internal/models"
+)
+
+type UserSQL struct {
+	db *sql.DB
+}
+
+func NewUserSQL(db *sql.DB) *UserSQL {
+	return &UserSQL{db: db}
+}
+
+func (r *UserSQL) Create(user models.User, activationLink string) (int64, error) {
+	insertedUser, err := r.db.Exec("INSERT INTO users(username, email, password, activation_link) VALUES(?, ?, ?, ?)", user.Username, user.Email, user.Password, activationLink)
+	if err != nil {
+		return 0, err
+	}
+
+	return insertedUser.LastInsertId()
+}
+
+func (r *UserSQL) FindByID(userID int64) (*models.User, error) {
+	var user models.User
+	err := r.db.QueryRow("SELECT id, username, email, avatar, role, created_at FROM users WHERE id = ?", userID).Scan(&user.ID, &user.Username, &user.Email, &user.Avatar, &user.Role, &user.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *UserSQL) FindByUsername(username string) (*models.User, error) {
+	var user models.User
+	err := r.db.QueryRow("SELECT id, username, email, avatar, role, created_at FROM users WHERE username = ?", username).Scan(&user.ID, &user.Username, &user.Email, &user.Avatar, &user.Role, &user.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *UserSQL) FindCredentials(username string, email string) (*models.User, error) {
+	var user models.User
+	err := r.db.QueryRow("SELECT id, username, password, avatar FROM users WHERE username = ? OR email = ?", username, email).Scan(&user.ID, &user.Username, &user.Password, &user.Avatar)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *UserSQL) FindPassword(userID int64) (string, error) {
+	var password string
+	if err := r.db.QueryRow("SELECT password FROM users WHERE id = ?", userID).Scan(&password); err != nil {
+		return "", err
+	}
+	return password, nil
+}
+
+func (r *UserSQL) Exists(userID int64) (bool, error) {
+	var exists bool
+	if err := r.db.QueryRow("SELECT EXISTS(SELECT 1 FROM users WHERE id = ?)", userID).Scan(&exists); err != nil {
+		return false, err
+	}
+	return exists, nil
+}
+
+func (r *UserSQL) SetAvatar(userID int64, avatar string) error {
+	_, err := r.db.Exec("UPDATE users SET avatar = ? WHERE id = ?", avatar, userID)
+	return err
+}
+
+func (r *UserSQL) Delete(userID int64) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	queries := [34]string{
+		"DELETE FROM users WHERE id = ?",
+		"DELETE FROM access_tokens WHERE user_id = ?",
+		"DELETE FROM two_factor_challenges WHERE user_id = ?",
+		"DELETE FROM recovery_codes WHERE user_id = ?",
+		"DELETE FROM two_factor WHERE user_id = ?",
+		"DELETE FROM refresh_tokens WHERE session_id IN (SELECT id FROM sessions WHERE user_id = ?)",
+		"DELETE FROM sessions WHERE user_id = ?",
+		"DELETE FROM notification_actors WHERE notification_id IN (SELECT id FROM notifications WHERE user_id = ?)",
+		"DELETE FROM notifications WHERE user_id = ?",
+		"DELETE FROM notification_actors WHERE notification_id IN (SELECT n.id FROM notifications n JOIN posts p ON p.id = n.post_id WHERE p.author_id = ?)",
+		"DELETE FROM notifications WHERE post_id IN (SELECT id FROM posts WHERE author_id = ?)",
+		// Coalesced notifications lose the user as an actor and are dropped once no actor is left
+		"DELETE FROM notification_actors WHERE actor_id = ?",
+		"DELETE FROM notifications WHERE actor_id = ? AND id NOT IN (SELECT notification_id FROM notification_actors)",
+		"UPDATE notifications SET actor_id = (SELECT MAX(a.actor_id) FROM notification_actors a WHERE a.notification_id = notifications.id) WHERE actor_id = ?",
+		"DELETE FROM bookmarks WHERE user_id = ?",
+		"DELETE FROM bookmark_collections WHERE user_id = ?",
+		"DELETE FROM bookmarks WHERE post_id IN (SELECT id FROM posts WHERE author_id = ?)",
+		"DELETE FROM post_revisions WHERE post_id IN (SELECT id FROM posts WHERE author_id = ?)",
+		"DELETE FROM post_tags WHERE post_id IN (SELECT id FROM posts WHERE author_id = ?)",
+		"DELETE FROM search_index WHERE post_id IN (SELECT id FROM posts WHERE author_id = ?)",
+		"DELETE FROM search_index WHERE source = 'comment' AND source_id IN (SELECT id FROM comments WHERE author_id = ?)",
+		"DELETE FROM comment_revisions WHERE comment_id IN (SELECT c.id FROM comments c JOIN posts p ON p.id = c.post_id WHERE p.author_id = ?)",
+		"DELETE FROM comment_revisions WHERE comment_id IN (SELECT id FROM comments WHERE author_id = ?)",
+		"DELETE FROM reactions WHERE target_type = 'comment' AND target_id IN (SELECT c.id FROM comments c JOIN posts p ON p.id = c.post_id WHERE p.author_id = ?)",
+		"DELETE FROM reactions WHERE target_type = 'comment' AND target_id IN (SELECT id FROM comments WHERE author_id = ?)",
+		"DELETE FROM reactions WHERE target_type = 'post' AND target_id IN (SELECT id FROM posts WHERE author_id = ?)",
+		"DELETE FROM reactions WHERE user_id = ?",
+		// Comments on the user's posts reference them, so they go before the posts
+		"DELETE FROM comments WHERE post_id IN (SELECT id FROM posts WHERE author_id = ?)",
+		// Comments that others replied to stay behind as tombstones
+		"UPDATE comments SET deleted = TRUE, text = '', author_id = 0 WHERE author_id = ? AND id IN (SELECT parent_id FROM (SELECT parent_id FROM comments WHERE parent_id IS NOT NULL) parents)",
+		"DELETE FROM likes WHERE post_id IN (SELECT id FROM posts WHERE author_id = ?)",
+		"DELETE FROM posts WHERE author_id = ?",
+		"DELETE FROM comments WHERE author_id = ?",
+		"UPDATE posts SET likes = likes - 1 WHERE id IN (SELECT post_id FROM likes WHERE user_id = ?)",
+		"DELETE FROM likes WHERE user_id = ?",
+	}
+
+	for _, query := range queries {
+		_, err := tx.Exec(query, userID)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.Exec("DELETE FROM followers WHERE user_id = ? OR following_id = ?", userID, userID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+)
+
+func tableNames(t *testing.T, conn *sql.DB) []string {
+	t.Helper()
+
+	rows, err := conn.Query("SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT IN ('sqlite_sequence', 'schema_migrations') ORDER BY name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, name)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return names
+}
+
+// TestDeleteUserLeavesNoRowsBehind deletes a user with rows in every table and
+// checks that nothing refers to them, their posts or their comments afterwards,
+// while the rows of other users stay. A table added without rows in this test
+// fails it, so that deleting users is checked against the new table too.
+func TestDeleteUserLeavesNoRowsBehind(t *testing.T) {
+	conn := newTestDB(t)
+
+	exec := func(query string, args ...interface{}) {
+		t.Helper()
+		if _, err := conn.Exec(query, args...); err != nil {
+			t.Fatalf("%s: %v", query, err)
+		}
+	}
+
+	alice := createTestUser(t, conn, "alice")
+	bob := createTestUser(t, conn, "bob")
+	carol := createTestUser(t, conn, "carol")
+	alicePost := createTestPost(t, conn, alice)
+	bobPost := createTestPost(t, conn, bob)
+	at := time.Now().UTC().Truncate(time.Second)
+
+	exec("INSERT INTO tags(id, name) VALUES (1, 'go')")
+	exec("INSERT INTO post_tags(post_id, tag_id) VALUES (?, 1), (?, 1)", alicePost, bobPost)
+
+	// Bob on alice's post; alice on bob's post, once with a reply from bob and
+	// once without; bob on his own post
+	exec("INSERT INTO comments(id, post_id, parent_id, author_id, text) VALUES (1, ?, NULL, ?, 'one'), (2, ?, NULL, ?, 'two'), (3, ?, 2, ?, 'three'), (4, ?, NULL, ?, 'four'), (5, ?, NULL, ?, 'five')",
+		alicePost, bob, bobPost, alice, bobPost, bob, bobPost, alice, bobPost, bob)
+	exec("INSERT INTO comment_revisions(comment_id, text, created_at) VALUES (1, 'one', ?), (4, 'four', ?), (5, 'five', ?)", at, at, at)
+	exec("INSERT INTO reactions(user_id, target_type, target_id, reaction) VALUES (?, 'post', ?, 'heart'), (?, 'post', ?, 'heart'), (?, 'comment', 1, 'heart'), (?, 'comment', 4, 'heart'), (?, 'comment', 5, 'heart'), (?, 'post', ?, 'heart')",
+		alice, bobPost, bob, alicePost, bob, bob, bob, bob, bobPost)
+	exec("INSERT INTO likes(user_id, post_id) VALUES (?, ?), (?, ?), (?, ?)", alice, bobPost, bob, alicePost, carol, bobPost)
+	exec("UPDATE posts SET likes = (SELECT COUNT(*) FROM likes WHERE likes.post_id = posts.id)")
+	exec("INSERT INTO followers(user_id, following_id) VALUES (?, ?), (?, ?), (?, ?)", alice, bob, bob, alice, carol, bob)
+	exec("INSERT INTO bookmark_collections(id, user_id, name, created_at) VALUES (1, ?, 'mine', ?), (2, ?, 'mine', ?)", alice, at, bob, at)
+	exec("INSERT INTO bookmarks(user_id, post_id, collection_id, created_at) VALUES (?, ?, 1, ?), (?, ?, 2, ?), (?, ?, 2, ?)",
+		alice, bobPost, at, bob, alicePost, at, bob, bobPost, at)
+
+	// To alice; to bob about alice's post; to bob from alice alone; to bob from alice and carol
+	exec("INSERT INTO notifications(id, user_id, kind, actor_id, post_id, created_at, updated_at) VALUES (1, ?, 'like', ?, ?, ?, ?), (2, ?, 'comment', ?, ?, ?, ?), (3, ?, 'follow', ?, NULL, ?, ?), (4, ?, 'like', ?, ?, ?, ?)",
+		alice, bob, alicePost, at, at, bob, carol, alicePost, at, at, bob, alice, at, at, bob, alice, bobPost, at, at)
+	exec("INSERT INTO notification_actors(notification_id, actor_id) VALUES (1, ?), (2, ?), (3, ?), (4, ?), (4, ?)", bob, carol, alice, alice, carol)
+
+	exec("INSERT INTO search_index(post_id, source, source_id, term, position) VALUES (?, 'title', ?, 'post', 0), (?, 'comment', 1, 'one', 0), (?, 'title', ?, 'post', 0), (?, 'comment', 4, 'four', 0), (?, 'comment', 5, 'five', 0)",
+		alicePost, alicePost, alicePost, bobPost, bobPost, bobPost, bobPost)
+	exec("INSERT INTO sessions(id, user_id, created_at) VALUES (1, ?, ?), (2, ?, ?)", alice, at, bob, at)
+	exec("INSERT INTO refresh_tokens(session_id, token_hash, created_at, expires_at) VALUES (1, 'a', ?, ?), (2, 'b', ?, ?)", at, at, at, at)
+	exec("INSERT INTO access_tokens(user_id, name, token_prefix, token_hash, scopes, created_at, expires_at) VALUES (?, 'a', 'a', 'a', 'posts:read', ?, ?), (?, 'b', 'b', 'b', 'posts:read', ?, ?)", alice, at, at, bob, at, at)
+	exec("INSERT INTO two_factor(user_id, secret, created_at) VALUES (?, 'a', ?), (?, 'b', ?)", alice, at, bob, at)
+	exec("INSERT INTO recovery_codes(user_id, code_hash) VALUES (?, 'a'), (?, 'b')", alice, bob)
+	exec("INSERT INTO two_factor_challenges(user_id, token_hash, expires_at) VALUES (?, 'a', ?), (?, 'b', ?)", alice, at, bob, at)
+
+	tables := tableNames(t, conn)
+	for _, table := range tables {
+		if queryInt(t, conn, "SELECT COUNT(*) FROM "+table) == 0 {
+			t.Fatalf("table %s has no rows: add some to this test, owned by both the deleted user and another one", table)
+		}
+	}
+
+	if err := NewUserSQL(conn).Delete(alice); err != nil {
+		t.Fatal(err)
+	}
+
+	// Rows naming the user in any column that refers to users
+	for _, table := range tables {
+		rows, err := conn.Query("SELECT name FROM pragma_table_info(?) WHERE name IN ('user_id', 'author_id', 'actor_id', 'following_id')", table)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var columns []string
+		for rows.Next() {
+			var column string
+			if err := rows.Scan(&column); err != nil {
+				t.Fatal(err)
+			}
+			columns = append(columns, column)
+		}
+		rows.Close()
+
+		for _, column := range columns {
+			if count := queryInt(t, conn, "SELECT COUNT(*) FROM "+table+" WHERE "+column+" = ?", alice); count != 0 {
+				t.Errorf("%s still has %d row(s) with %s of the deleted user", table, count, column)
+			}
+		}
+		if table == "users" && queryInt(t, conn, "SELECT COUNT(*) FROM users WHERE id = ?", alice) != 0 {
+			t.Error("the user was not deleted")
+		}
+	}
+
+	orphans := map[string]string{
+		"post_tags": "SELECT COUNT(*) FROM post_tags WHERE post_id NOT IN (SELECT id FROM posts)",
+		"post_revisions": "SELECT COUNT(*) FROM post_revisions WHERE post_id NOT IN (SELECT id FROM posts)",
+		"comments": "SELECT COUNT(*) FROM comments WHERE post_id NOT IN (SELECT id FROM posts)",
+		"comment_revisions": "SELECT COUNT(*) FROM comment_revisions WHERE comment_id NOT IN (SELECT id FROM comments)",
+		"likes": "SELECT COUNT(*) FROM likes WHERE post_id NOT IN (SELECT id FROM posts)",
+		"bookmarks": "SELECT COUNT(*) FROM bookmarks WHERE post_id NOT IN (SELECT id FROM posts) OR collection_id NOT IN (SELECT id FROM bookmark_collections)",
+		"post reactions": "SELECT COUNT(*) FROM reactions WHERE target_type = 'post' AND target_id NOT IN (SELECT id FROM posts)",
+		"comment reactions": "SELECT COUNT(*) FROM reactions WHERE target_type = 'comment' AND target_id NOT IN (SELECT id FROM comments)",
+		"notifications": "SELECT COUNT(*) FROM notifications WHERE post_id NOT IN (SELECT id FROM posts) OR id NOT IN (SELECT notification_id FROM notification_actors)",
+		"notification_actors": "SELECT COUNT(*) FROM notification_actors WHERE notification_id NOT IN (SELECT id FROM notifications)",
+		"search_index": "SELECT COUNT(*) FROM search_index WHERE post_id NOT IN (SELECT id FROM posts) OR (source = 'comment' AND source_id NOT IN (SELECT id FROM comments WHERE deleted = FALSE))",
+		"refresh_tokens": "SELECT COUNT(*) FROM refresh_tokens WHERE session_id NOT IN (SELECT id FROM sessions)",
+		"post likes": "SELECT COUNT(*) FROM posts WHERE likes <> (SELECT COUNT(*) FROM likes WHERE likes.post_id = posts.id)",
+	}
+	for name, query := range orphans {
+		if count := queryInt(t, conn, query); count != 0 {
+			t.Errorf("%s: %d row(s) left pointing at deleted rows", name, count)
+		}
+	}
+
+	// The comment bob replied to stays as a tombstone
+	if queryInt(t, conn, "SELECT COUNT(*) FROM comments WHERE id = 2 AND deleted = TRUE AND author_id = 0") != 1 {
+		t.Error("the replied-to comment was not kept as a tombstone")
+	}
+	// The notification alice shared with carol is now carol's
+	if queryInt(t, conn, "SELECT actor_id FROM notifications WHERE id = 4") != carol {
+		t.Error("the coalesced notification did not move to the remaining actor")
+	}
+
+	for _, table := range tables {
+		if queryInt(t, conn, "SELECT COUNT(*) FROM "+table) == 0 {
+			t.Errorf("deleting the user emptied %s, which also had rows of other users", table)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/morf1lo/blog-app/internal/models"
+	"github.com/morf1lo/blog-app/internal/repository"
+	"github.com/morf1lo/blog-app/internal/utils/auth"
+)
+
+type AccessTokenService struct {
+	tokens repository.AccessToken
+}
+
+func NewAccessTokenService(tokens repository.AccessToken) *AccessTokenService {
+	return &AccessTokenService{tokens: tokens}
+}
+
+// CreateAccessToken mints a personal access token. The returned token is the
+// only copy of its secret.
+func (s *AccessTokenService) CreateAccessToken(userID int64, options models.AccessTokenOptions) (*models.AccessToken, error) {
+	scopes := make([]models.Scope, 0, len(options.Scopes))
+	for _, scope := range options.Scopes {
+		if !scope.Valid() {
+			return nil, fmt.Errorf("%w %q", errUnknownScope, scope)
+		}
+		if !containsScope(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	secret, prefix, err := auth.GeneratePersonalAccessToken()
+	if err != nil {
+		return nil, err
+	}
+
+	createdAt := now()
+	token := models.AccessToken{
+		UserID: userID,
+		Name: strings.TrimSpace(options.Name),
+		Prefix: prefix,
+		Scopes: scopes,
+		CreatedAt: createdAt,
+		ExpiresAt: createdAt.AddDate(0, 0, options.ExpiresInDays),
+	}
+
+	token.ID, err = s.tokens.Create(token, auth.HashToken(secret))
+	if err != nil {
+		return nil, err
+	}
+
+	token.Token = secret
+	return &token, nil
+}
+
+func (s *AccessTokenService) FindAccessTokens(userID int64) ([]models.AccessToken, error) {
+	return s.tokens.FindByUser(userID)
+}
+
+func (s *AccessTokenService) RevokeAccessToken(tokenID int64, userID int64) error {
+	revoked, err := s.tokens.Revoke(tokenID, userID, now())
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return errAccessTokenNotFound
+	}
+	return nil
+}
+
+// AuthenticateAccessToken looks up a personal access token that is neither
+// revoked nor expired and records that it was used.
+func (s *AccessTokenService) AuthenticateAccessToken(secret string) (*models.AccessToken, error) {
+	token, err := s.tokens.FindByHash(auth.HashToken(secret))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errInvalidAccessToken
+		}
+		return nil, err
+	}
+
+	usedAt := now()
+	if token.RevokedAt != nil {
+		return nil, errInvalidAccessToken
+	}
+	if !usedAt.Before(token.ExpiresAt) {
+		return nil, errAccessTokenExpired
+	}
+
+	if err := s.tokens.Touch(token.ID, usedAt, usedAt.Add(-touchInterval)); err != nil {
+		return nil, err
+	}
+
+	return token, nil
+}
+
+func containsScope(scopes []models.Scope, scope models.Scope) bool {
+	for _, s := range scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"github.com/morf1lo/blog-app/internal/models"
+	"github.com/morf1lo/blog-app/internal/repository"
+)
+
+// PostAnnotator fills in the parts of post payloads that depend on who is
+// viewing them: reaction counts, the viewer's own reactions and bookmarks.
+type PostAnnotator struct {
+	reactions *ReactionService
+	bookmarks repository.Bookmark
+}
+
+func NewPostAnnotator(reactions *ReactionService, bookmarks repository.Bookmark) *PostAnnotator {
+	return &PostAnnotator{reactions: reactions, bookmarks: bookmarks}
+}
+
+func (a *PostAnnotator) Annotate(posts []models.Post, viewerID int64) error {
+	if err := a.reactions.AttachToPosts(posts, viewerID); err != nil {
+		return err
+	}
+
+	postIDs := make([]int64, len(posts))
+	for i := range posts {
+		postIDs[i] = posts[i].ID
+	}
+
+	bookmarked, err := a.bookmarks.Bookmarked(viewerID, postIDs)
+	if err != nil {
+		return err
+	}
+
+	for i := range posts {
+		posts[i].IsBookmarked = bookmarked[posts[i].ID]
+	}
+	return nil
+}
+
+// page wraps a repository call returning a page of posts so that the posts
+// come back annotated for the viewer.
+func (a *PostAnnotator) page(viewerID int64) func(*models.Page[models.Post], error) (*models.Page[models.Post], error) {
+	return func(page *models.Page[models.Post], err error) (*models.Page[models.Post], error) {
+		if err != nil {
+			return nil, err
+		}
+		if err := a.Annotate(page.Items, viewerID); err != nil {
+			return nil, err
+		}
+		return page, nil
+	}
+}
@@ -2,56 +2,56 @@ package service
 
 import (
 	"database/sql"
+	"errors"
+	"strings"
 	"time"
 
+	"github.com/morf1lo/blog-app/internal/config"
 	"github.com/morf1lo/blog-app/internal/models"
+	"github.com/morf1lo/blog-app/internal/repository"
 	"github.com/morf1lo/blog-app/internal/utils/auth"
 )
 
+// touchInterval limits how often the last time a session or an access token
+// was used is written.
+const touchInterval = time.Minute
+
+const maxUserAgentLength = 255
+
 type AuthService struct {
-	db *sql.DB
+	users    repository.User
+	tokens   repository.Token
+	sessions repository.Session
+	cfg      config.AuthConfig
 }
 
-func NewAuthService(db *sql.DB) *AuthService {
+func NewAuthService(users repository.User, tokens repository.Token, sessions repository.Session, cfg config.AuthConfig) *AuthService {
 	return &AuthService{
-		db: db,
+		users: users,
+		tokens: tokens,
+		sessions: sessions,
+		cfg: cfg,
 	}
 }
 
 func (s *AuthService) CreateUser(user models.User, activationLink string) (int64, error) {
-	insertedUser, err := s.db.Exec("INSERT INTO users(username, email, password, activation_link) VALUES(?, ?, ?, ?)", user.Username, user.Email, user.Password, activationLink)
-	if err != nil {
-		return 0, err
-	}
-
-	id, err := insertedUser.LastInsertId()
-	if err != nil {
-		return 0, err
-	}
-
-	return id, nil
+	return s.users.Create(user, activationLink)
 }
 
 func (s *AuthService) Activate(activationLink string) error {
-	var exists bool
-	if err := s.db.QueryRow("SELECT EXISTS(SELECT 1 FROM users WHERE activation_link = ?)", activationLink).Scan(&exists); err != nil {
+	exists, err := s.tokens.ActivationLinkExists(activationLink)
+	if err != nil {
 		return errInternalServer
 	}
 	if !exists {
 		return errUserNotFound
 	}
 
-	_, err := s.db.Exec("UPDATE users SET activated = true, activation_link = null WHERE activation_link = ?", activationLink)
-	if err != nil {
-		return err
-	}
-
-	return nil
+	return s.tokens.Activate(activationLink)
 }
 
 func (s *AuthService) SignIn(user models.User) (int64, error) {
-	var existingUser models.User
-	err := s.db.QueryRow("SELECT id, username, password, avatar FROM users WHERE username = ? OR email = ?", user.Username, user.Email).Scan(&existingUser.ID, &existingUser.Username, &existingUser.Password, &existingUser.Avatar)
+	existingUser, err := s.users.FindCredentials(user.Username, user.Email)
 	if err != nil {
 		return 0, errInvalidCredenials
 	}
@@ -65,29 +65,17 @@ func (s *AuthService) SignIn(user models.User) (int64, error) {
 }
 
 func (s *AuthService) SaveResetToken(email string, token string, tokenExpiry time.Time) error {
-	_, err := s.db.Exec("UPDATE users SET reset_token = ?, reset_token_expiry = ? WHERE email = ?", token, tokenExpiry, email)
-	if err != nil {
-		return err
-	}
-	return nil
+	return s.tokens.SaveResetToken(email, token, tokenExpiry)
 }
 
 func (s *AuthService) ResetPassword(token string, newPassword string) error {
-	var user models.User
-	var resetTokenExpiryStr string
-	if err := s.db.QueryRow("SELECT id, reset_token, reset_token_expiry FROM users WHERE reset_token = ?", token).Scan(&user.ID, &user.ResetToken, &resetTokenExpiryStr); err != nil {
-		return err
-	}
-
-	resetTokenExpiry, err := time.Parse("2006-01-02 15:04:05", resetTokenExpiryStr)
+	userID, resetTokenExpiry, err := s.tokens.FindResetToken(token)
 	if err != nil {
 		return err
 	}
-	user.ResetTokenExpiry = resetTokenExpiry
 
-	if time.Now().After(user.ResetTokenExpiry) {
-		_, err := s.db.Exec("UPDATE users SET reset_token = null, reset_token_expiry = null WHERE reset_token = ?", token)
-		if err != nil {
+	if time.Now().After(resetTokenExpiry) {
+		if err := s.tokens.ClearResetToken(token); err != nil {
 			return err
 		}
 		return errTokenHasExpired
@@ -98,10 +86,165 @@ func (s *AuthService) ResetPassword(token string, newPassword string) error {
 		return err
 	}
 
-	_, err = s.db.Exec("UPDATE users SET password = ?, reset_token = null, reset_token_expiry = null WHERE id = ?", hash, user.ID)
+	if err := s.tokens.ResetPassword(userID, hash); err != nil {
+		return err
+	}
+
+	// Whoever knew the old password may still be signed in
+	return s.sessions.RevokeAll(userID, 0, now())
+}
+
+// CreateSession signs a user in, issuing an access token and the first
+// refresh token of a new session.
+func (s *AuthService) CreateSession(userID int64, userAgent string, ip string) (*models.SessionTokens, error) {
+	refreshToken, err := auth.GenerateRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	createdAt := now()
+	refreshExpiresAt := createdAt.Add(s.cfg.RefreshTokenTTL)
+
+	session := models.Session{
+		UserID: userID,
+		UserAgent: truncateUserAgent(userAgent),
+		IP: ip,
+		CreatedAt: createdAt,
+	}
+
+	sessionID, err := s.sessions.Create(session, auth.HashToken(refreshToken), refreshExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.issueTokens(userID, sessionID, refreshToken, createdAt, refreshExpiresAt)
+}
+
+// RefreshSession exchanges a refresh token for new tokens. Each refresh token
+// works once: presenting a used one means it was stolen or replayed, so the
+// whole session is revoked.
+func (s *AuthService) RefreshSession(refreshToken string, ip string) (*models.SessionTokens, error) {
+	token, err := s.sessions.FindRefreshToken(auth.HashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errInvalidRefreshToken
+		}
+		return nil, err
+	}
+
+	refreshedAt := now()
+	if token.Revoked {
+		return nil, errSessionRevoked
+	}
+	if token.UsedAt != nil {
+		return nil, s.revokeReusedSession(token)
+	}
+	if !refreshedAt.Before(token.ExpiresAt) {
+		return nil, errTokenHasExpired
+	}
+
+	newRefreshToken, err := auth.GenerateRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	refreshExpiresAt := refreshedAt.Add(s.cfg.RefreshTokenTTL)
+	rotated, err := s.sessions.Rotate(token.ID, token.SessionID, auth.HashToken(newRefreshToken), refreshedAt, refreshExpiresAt)
 	if err != nil {
+		return nil, err
+	}
+	if !rotated {
+		return nil, s.revokeReusedSession(token)
+	}
+
+	if err := s.sessions.Touch(token.SessionID, ip, refreshedAt, refreshedAt); err != nil {
+		return nil, err
+	}
+
+	return s.issueTokens(token.UserID, token.SessionID, newRefreshToken, refreshedAt, refreshExpiresAt)
+}
+
+func (s *AuthService) revokeReusedSession(token *models.RefreshToken) error {
+	if _, err := s.sessions.Revoke(token.SessionID, token.UserID, now()); err != nil {
 		return err
 	}
+	return errRefreshTokenReused
+}
+
+func (s *AuthService) issueTokens(userID int64, sessionID int64, refreshToken string, issuedAt time.Time, refreshExpiresAt time.Time) (*models.SessionTokens, error) {
+	accessExpiresAt := issuedAt.Add(s.cfg.AccessTokenTTL)
+	accessToken, err := auth.GenerateAccessToken(userID, sessionID, s.cfg.Secret, accessExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.SessionTokens{
+		UserID: userID,
+		SessionID: sessionID,
+		AccessToken: accessToken,
+		AccessExpiresAt: accessExpiresAt,
+		RefreshToken: refreshToken,
+		RefreshExpiresAt: refreshExpiresAt,
+	}, nil
+}
+
+// Authenticate verifies an access token and checks that its session has not
+// been revoked, recording when and from where the session was last used.
+func (s *AuthService) Authenticate(accessToken string, ip string) (int64, int64, error) {
+	userID, sessionID, err := auth.ParseAccessToken(accessToken, s.cfg.Secret)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	active, err := s.sessions.IsActive(sessionID, userID)
+	if err != nil {
+		return 0, 0, err
+	}
+	if !active {
+		return 0, 0, errSessionRevoked
+	}
+
+	seenAt := now()
+	if err := s.sessions.Touch(sessionID, ip, seenAt, seenAt.Add(-touchInterval)); err != nil {
+		return 0, 0, err
+	}
+
+	return userID, sessionID, nil
+}
+
+// ListSessions returns the active sessions of a user, flagging the one making the request.
+func (s *AuthService) ListSessions(userID int64, currentSessionID int64) ([]models.Session, error) {
+	sessions, err := s.sessions.FindActive(userID, now())
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == currentSessionID
+	}
 
+	return sessions, nil
+}
+
+func (s *AuthService) RevokeSession(sessionID int64, userID int64) error {
+	revoked, err := s.sessions.Revoke(sessionID, userID, now())
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return errSessionNotFound
+	}
 	return nil
 }
+
+// RevokeOtherSessions signs a user out everywhere except the current session.
+func (s *AuthService) RevokeOtherSessions(userID int64, currentSessionID int64) error {
+	return s.sessions.RevokeAll(userID, currentSessionID, now())
+}
+
+func truncateUserAgent(userAgent string) string {
+	if len(userAgent) <= maxUserAgentLength {
+		return userAgent
+	}
+	return strings.ToValidUTF8(userAgent[:maxUserAgentLength], "")
+}
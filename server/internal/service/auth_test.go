@@ -0,0 +1,242 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/morf1lo/blog-app/internal/config"
+	"github.com/morf1lo/blog-app/internal/models"
+	"github.com/morf1lo/blog-app/internal/repository"
+)
+
+// fakeUsers keeps users in memory. Methods the tests do not need are left to
+// the embedded nil interface and panic when called.
+type fakeUsers struct {
+	repository.User
+	users []models.User
+}
+
+func (f *fakeUsers) FindCredentials(username string, email string) (*models.User, error) {
+	for i := range f.users {
+		if f.users[i].Username == username || f.users[i].Email == email {
+			user := f.users[i]
+			return &user, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+type fakeRefreshToken struct {
+	models.RefreshToken
+	hash string
+}
+
+// fakeSessions is an in-memory repository.Session with the same rules as SessionSQL.
+type fakeSessions struct {
+	mu       sync.Mutex
+	sessions []models.Session
+	revoked  map[int64]bool
+	tokens   []fakeRefreshToken
+}
+
+func newFakeSessions() *fakeSessions {
+	return &fakeSessions{revoked: make(map[int64]bool)}
+}
+
+func (f *fakeSessions) Create(session models.Session, tokenHash string, expiresAt time.Time) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	session.ID = int64(len(f.sessions) + 1)
+	f.sessions = append(f.sessions, session)
+	f.addToken(session.ID, session.UserID, tokenHash, expiresAt)
+	return session.ID, nil
+}
+
+func (f *fakeSessions) addToken(sessionID int64, userID int64, tokenHash string, expiresAt time.Time) {
+	f.tokens = append(f.tokens, fakeRefreshToken{
+		RefreshToken: models.RefreshToken{ID: int64(len(f.tokens) + 1), SessionID: sessionID, UserID: userID, ExpiresAt: expiresAt},
+		hash: tokenHash,
+	})
+}
+
+func (f *fakeSessions) FindRefreshToken(tokenHash string) (*models.RefreshToken, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, token := range f.tokens {
+		if token.hash == tokenHash {
+			found := token.RefreshToken
+			found.Revoked = f.revoked[token.SessionID]
+			return &found, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (f *fakeSessions) Rotate(tokenID int64, sessionID int64, newTokenHash string, usedAt time.Time, expiresAt time.Time) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	token := &f.tokens[tokenID-1]
+	if token.UsedAt != nil {
+		return false, nil
+	}
+	token.UsedAt = &usedAt
+	f.addToken(sessionID, token.UserID, newTokenHash, expiresAt)
+	return true, nil
+}
+
+func (f *fakeSessions) IsActive(sessionID int64, userID int64) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	session := f.find(sessionID, userID)
+	return session != nil && !f.revoked[sessionID], nil
+}
+
+func (f *fakeSessions) FindActive(userID int64, now time.Time) ([]models.Session, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	sessions := []models.Session{}
+	for _, session := range f.sessions {
+		if session.UserID == userID && !f.revoked[session.ID] {
+			sessions = append(sessions, session)
+		}
+	}
+	return sessions, nil
+}
+
+func (f *fakeSessions) Touch(sessionID int64, ip string, seenAt time.Time, staleBefore time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	session := &f.sessions[sessionID-1]
+	session.IP = ip
+	session.LastSeenAt = &seenAt
+	return nil
+}
+
+func (f *fakeSessions) Revoke(sessionID int64, userID int64, revokedAt time.Time) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.find(sessionID, userID) == nil || f.revoked[sessionID] {
+		return false, nil
+	}
+	f.revoked[sessionID] = true
+	return true, nil
+}
+
+func (f *fakeSessions) RevokeAll(userID int64, exceptSessionID int64, revokedAt time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, session := range f.sessions {
+		if session.UserID == userID && session.ID != exceptSessionID {
+			f.revoked[session.ID] = true
+		}
+	}
+	return nil
+}
+
+func (f *fakeSessions) find(sessionID int64, userID int64) *models.Session {
+	for i := range f.sessions {
+		if f.sessions[i].ID == sessionID && f.sessions[i].UserID == userID {
+			return &f.sessions[i]
+		}
+	}
+	return nil
+}
+
+func newTestAuthService(t *testing.T) (*AuthService, *fakeSessions) {
+	t.Helper()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("correct horse"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	users := &fakeUsers{users: []models.User{
+		{ID: 1, Username: "alice", Email: "alice@example.com", Password: string(hash)},
+	}}
+	sessions := newFakeSessions()
+	cfg := config.AuthConfig{Secret: "test secret", AccessTokenTTL: time.Minute, RefreshTokenTTL: time.Hour}
+
+	return NewAuthService(users, nil, sessions, cfg), sessions
+}
+
+func TestSignIn(t *testing.T) {
+	s, _ := newTestAuthService(t)
+
+	userID, err := s.SignIn(models.User{Email: "alice@example.com", Password: "correct horse"})
+	if err != nil || userID != 1 {
+		t.Fatalf("SignIn = %d, %v, want 1", userID, err)
+	}
+
+	if _, err := s.SignIn(models.User{Username: "alice", Password: "wrong"}); !errors.Is(err, errInvalidCredenials) {
+		t.Errorf("wrong password: got %v, want %v", err, errInvalidCredenials)
+	}
+	if _, err := s.SignIn(models.User{Username: "bob", Password: "correct horse"}); !errors.Is(err, errInvalidCredenials) {
+		t.Errorf("unknown user: got %v, want %v", err, errInvalidCredenials)
+	}
+}
+
+func TestRefreshSessionRevokesReusedToken(t *testing.T) {
+	s, _ := newTestAuthService(t)
+
+	first, err := s.CreateSession(1, "test", "127.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := s.Authenticate(first.AccessToken, "127.0.0.1"); err != nil {
+		t.Fatalf("Authenticate with a fresh session: %v", err)
+	}
+
+	second, err := s.RefreshSession(first.RefreshToken, "127.0.0.2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.SessionID != first.SessionID || second.RefreshToken == first.RefreshToken {
+		t.Fatalf("refresh did not rotate the token within the session")
+	}
+
+	if _, err := s.RefreshSession(first.RefreshToken, "127.0.0.3"); !errors.Is(err, errRefreshTokenReused) {
+		t.Fatalf("reusing a refresh token: got %v, want %v", err, errRefreshTokenReused)
+	}
+	if _, _, err := s.Authenticate(second.AccessToken, "127.0.0.2"); !errors.Is(err, errSessionRevoked) {
+		t.Errorf("access token of a revoked session: got %v, want %v", err, errSessionRevoked)
+	}
+	if _, err := s.RefreshSession(second.RefreshToken, "127.0.0.2"); !errors.Is(err, errSessionRevoked) {
+		t.Errorf("latest refresh token of a revoked session: got %v, want %v", err, errSessionRevoked)
+	}
+}
+
+func TestRevokeSessionOfAnotherUser(t *testing.T) {
+	s, sessions := newTestAuthService(t)
+
+	tokens, err := s.CreateSession(1, "test", "127.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.RevokeSession(tokens.SessionID, 2); !errors.Is(err, errSessionNotFound) {
+		t.Fatalf("revoking another user's session: got %v, want %v", err, errSessionNotFound)
+	}
+	if sessions.revoked[tokens.SessionID] {
+		t.Fatal("another user revoked the session")
+	}
+
+	if err := s.RevokeSession(tokens.SessionID, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.RevokeSession(tokens.SessionID, 1); !errors.Is(err, errSessionNotFound) {
+		t.Errorf("revoking a session twice: got %v, want %v", err, errSessionNotFound)
+	}
+}
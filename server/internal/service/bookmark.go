@@ -0,0 +1,98 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/morf1lo/blog-app/internal/models"
+	"github.com/morf1lo/blog-app/internal/repository"
+)
+
+type BookmarkService struct {
+	bookmarks repository.Bookmark
+	posts     repository.Post
+	annotator *PostAnnotator
+}
+
+func NewBookmarkService(bookmarks repository.Bookmark, posts repository.Post, annotator *PostAnnotator) *BookmarkService {
+	return &BookmarkService{bookmarks: bookmarks, posts: posts, annotator: annotator}
+}
+
+// AddBookmark bookmarks a post, optionally in one of the user's collections.
+// Bookmarking a post again moves it to the given collection.
+func (s *BookmarkService) AddBookmark(postID int64, userID int64, collectionID *int64) error {
+	post, err := s.posts.FindByID(postID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return errPostNotFound
+		}
+		return err
+	}
+	if !post.VisibleTo(userID) {
+		return errPostNotFound
+	}
+
+	if collectionID != nil {
+		if _, err := s.findCollection(*collectionID, userID); err != nil {
+			return err
+		}
+	}
+
+	return s.bookmarks.Add(userID, postID, collectionID, now())
+}
+
+func (s *BookmarkService) RemoveBookmark(postID int64, userID int64) error {
+	return s.bookmarks.Remove(userID, postID)
+}
+
+func (s *BookmarkService) FindBookmarks(userID int64, collectionID *int64, pagination models.Pagination) (*models.Page[models.Post], error) {
+	if collectionID != nil {
+		if _, err := s.findCollection(*collectionID, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.annotator.page(userID)(s.bookmarks.FindBookmarkedPosts(userID, collectionID, pagination))
+}
+
+func (s *BookmarkService) CreateBookmarkCollection(collection models.BookmarkCollection) (*models.BookmarkCollection, error) {
+	collection.CreatedAt = now()
+
+	collectionID, err := s.bookmarks.CreateCollection(collection)
+	if err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return nil, errCollectionExists
+		}
+		return nil, err
+	}
+
+	collection.ID = collectionID
+	return &collection, nil
+}
+
+func (s *BookmarkService) FindBookmarkCollections(userID int64) ([]models.BookmarkCollection, error) {
+	return s.bookmarks.FindCollections(userID)
+}
+
+func (s *BookmarkService) DeleteBookmarkCollection(collectionID int64, userID int64) error {
+	if _, err := s.findCollection(collectionID, userID); err != nil {
+		return err
+	}
+
+	return s.bookmarks.DeleteCollection(collectionID, userID)
+}
+
+// findCollection returns a collection of the user; other users' collections are reported as missing.
+func (s *BookmarkService) findCollection(collectionID int64, userID int64) (*models.BookmarkCollection, error) {
+	collection, err := s.bookmarks.FindCollection(collectionID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errCollectionNotFound
+		}
+		return nil, err
+	}
+	if collection.UserID != userID {
+		return nil, errCollectionNotFound
+	}
+	return collection, nil
+}
@@ -0,0 +1,9 @@
+package service
+
+import "time"
+
+// now returns the current time in UTC truncated to whole seconds,
+// matching the precision of DATETIME columns.
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Second)
+}
@@ -2,103 +2,272 @@ package service
 
 import (
 	"database/sql"
-	"encoding/json"
+	"errors"
+	"time"
 
 	"github.com/morf1lo/blog-app/internal/models"
+	"github.com/morf1lo/blog-app/internal/repository"
 )
 
 type CommentService struct {
-	db *sql.DB
+	comments   repository.Comment
+	posts      repository.Post
+	users      repository.User
+	search     repository.Search
+	reactions     *ReactionService
+	notifications *NotificationService
+	editWindow    time.Duration
 }
 
-func NewCommentService(db *sql.DB) *CommentService {
-	return &CommentService{db: db}
+func NewCommentService(comments repository.Comment, posts repository.Post, users repository.User, search repository.Search, reactions *ReactionService, notifications *NotificationService, editWindow time.Duration) *CommentService {
+	return &CommentService{comments: comments, posts: posts, users: users, search: search, reactions: reactions, notifications: notifications, editWindow: editWindow}
 }
 
 func (s *CommentService) AddComment(comment models.Comment, userID int64, postID int64) error {
 	// Checking post existence
-	var exists bool
-	err := s.db.QueryRow("SELECT EXISTS(SELECT 1 FROM posts WHERE id = ?)", postID).Scan(&exists)
+	post, err := s.posts.FindByID(postID)
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return errPostNotFound
+		}
 		return err
 	}
-	if !exists {
+	if !post.VisibleTo(userID) {
 		return errPostNotFound
 	}
 
-	var postAuthorID int64
-	err = s.db.QueryRow("SELECT author_id FROM posts WHERE id = ?", postID).Scan(&postAuthorID)
-	if err != nil {
-		return err
+	var parentAuthorID int64
+	if comment.ParentID != nil {
+		parent, err := s.findComment(*comment.ParentID)
+		if err != nil {
+			return err
+		}
+		if parent.Post.ID != postID || parent.Deleted {
+			return errCommentNotFound
+		}
+		parentAuthorID = parent.AuthorID
 	}
 
-	postData := models.CommentPost{
+	comment.AuthorID = userID
+	comment.CreatedAt = now()
+	comment.Post = models.CommentPost{
 		ID: postID,
-		AuthorID: postAuthorID,
+		AuthorID: post.AuthorID,
 	}
-	postDataJSON, err := json.Marshal(postData)
+
+	commentID, err := s.comments.Create(comment)
 	if err != nil {
 		return err
 	}
+	comment.ID = commentID
 
-	_, err = s.db.Exec("INSERT INTO comments (post, author_id, text) VALUES(?, ?, ?)", postDataJSON, userID, comment.Text)
-	if err != nil {
+	if err := indexComment(s.search, &comment); err != nil {
 		return err
 	}
 
+	s.notifyComment(&comment, parentAuthorID)
 	return nil
 }
 
-func (s *CommentService) FindAllPostComments(postID int64) (*[]models.Comment, error) {
-	rows, err := s.db.Query("SELECT * FROM comments WHERE JSON_EXTRACT(post, '$.id') = ?", postID)
+// notifyComment tells the author of the parent comment about a reply, the
+// post author about a new comment and mentioned users about the mention,
+// each of them at most once.
+func (s *CommentService) notifyComment(comment *models.Comment, parentAuthorID int64) {
+	postID := comment.Post.ID
+	if comment.ParentID != nil {
+		s.notifications.notify(parentAuthorID, models.NotificationReply, comment.AuthorID, &postID, comment.ParentID)
+	}
+	if comment.Post.AuthorID != parentAuthorID {
+		s.notifications.notify(comment.Post.AuthorID, models.NotificationComment, comment.AuthorID, &postID, nil)
+	}
+
+	s.notifications.notifyMentions(comment.Text, comment.AuthorID, postID, &comment.ID, parentAuthorID, comment.Post.AuthorID)
+}
+
+func (s *CommentService) FindAllPostComments(postID int64, viewerID int64, pagination models.Pagination, tree models.CommentTreeOptions) (*models.Page[models.Comment], error) {
+	if err := s.checkPostVisible(postID, viewerID); err != nil {
+		return nil, err
+	}
+
+	comments, err := s.comments.FindByPost(postID, pagination)
+	if err != nil {
+		return nil, errInternalServer
+	}
+
+	if err := s.expandReplies(comments.Items, tree); err != nil {
+		return nil, errInternalServer
+	}
+
+	if err := s.reactions.AttachToComments(comments.Items, viewerID); err != nil {
+		return nil, errInternalServer
+	}
+
+	return comments, nil
+}
+
+func (s *CommentService) FindCommentReplies(postID int64, commentID int64, viewerID int64, pagination models.Pagination, tree models.CommentTreeOptions) (*models.Page[models.Comment], error) {
+	if err := s.checkPostVisible(postID, viewerID); err != nil {
+		return nil, err
+	}
+
+	comment, err := s.findComment(commentID)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
+	if comment.Post.ID != postID {
+		return nil, errCommentNotFound
+	}
 
-	var comments []models.Comment
-	for rows.Next() { 
-		var comment models.Comment
-		var postDataJSON string
-		if err := rows.Scan(&comment.ID, &postDataJSON, &comment.AuthorID, &comment.Text); err != nil {
-			return nil, err
+	replies, err := s.comments.FindReplies(commentID, pagination)
+	if err != nil {
+		return nil, errInternalServer
+	}
+
+	if err := s.expandReplies(replies.Items, tree); err != nil {
+		return nil, errInternalServer
+	}
+
+	if err := s.reactions.AttachToComments(replies.Items, viewerID); err != nil {
+		return nil, errInternalServer
+	}
+
+	return replies, nil
+}
+
+// expandReplies attaches the first page of replies to every comment, level by
+// level up to tree.Depth, with one query per level.
+func (s *CommentService) expandReplies(comments []models.Comment, tree models.CommentTreeOptions) error {
+	level := make([]*models.Comment, len(comments))
+	for i := range comments {
+		level[i] = &comments[i]
+	}
+
+	for depth := 0; depth < tree.Depth && len(level) > 0; depth++ {
+		var parentIDs []int64
+		for _, comment := range level {
+			if comment.ReplyCount > 0 {
+				parentIDs = append(parentIDs, comment.ID)
+			}
+		}
+
+		pages, err := s.comments.FindFirstReplies(parentIDs, tree.RepliesLimit)
+		if err != nil {
+			return err
 		}
 
-		if err := json.Unmarshal([]byte(postDataJSON), &comment.Post); err != nil {
-			return nil, errInternalServer
+		var next []*models.Comment
+		for _, comment := range level {
+			page, ok := pages[comment.ID]
+			if !ok {
+				continue
+			}
+
+			comment.Replies = page.Items
+			comment.RepliesCursor = page.NextCursor
+			for i := range comment.Replies {
+				next = append(next, &comment.Replies[i])
+			}
+		}
+		level = next
+	}
+
+	return nil
+}
+
+func (s *CommentService) findComment(commentID int64) (*models.Comment, error) {
+	comment, err := s.comments.FindByID(commentID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errCommentNotFound
+		}
+		return nil, err
+	}
+	return comment, nil
+}
+
+func (s *CommentService) checkPostVisible(postID int64, viewerID int64) error {
+	post, err := s.posts.FindByID(postID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return errPostNotFound
 		}
+		return err
+	}
+	if !post.VisibleTo(viewerID) {
+		return errPostNotFound
+	}
+	return nil
+}
+
+// EditComment lets the author change a comment within the configured edit window.
+func (s *CommentService) EditComment(commentID int64, userID int64, postID int64, text string) error {
+	comment, err := s.findComment(commentID)
+	if err != nil {
+		return err
+	}
+	if comment.Post.ID != postID || comment.Deleted {
+		return errCommentNotFound
+	}
+
+	if comment.AuthorID != userID {
+		return errNoAccess
+	}
 
-		comments = append(comments, comment)
+	editedAt := now()
+	if editedAt.Sub(comment.CreatedAt) > s.editWindow {
+		return errEditWindowExpired
 	}
 
-	if err := rows.Err(); err != nil {
+	if err := s.comments.Update(commentID, text, editedAt); err != nil {
+		return errInternalServer
+	}
+
+	comment.Text = text
+	return indexComment(s.search, comment)
+}
+
+// FindCommentRevisions returns the previous versions of a comment to its
+// author, the author of the post and moderators.
+func (s *CommentService) FindCommentRevisions(commentID int64, userID int64, postID int64, pagination models.Pagination) (*models.Page[models.CommentRevision], error) {
+	comment, err := s.findComment(commentID)
+	if err != nil {
 		return nil, err
 	}
+	if comment.Post.ID != postID {
+		return nil, errCommentNotFound
+	}
 
-	return &comments, nil
+	if comment.AuthorID != userID && comment.Post.AuthorID != userID {
+		user, err := s.users.FindByID(userID)
+		if err != nil {
+			return nil, err
+		}
+		if !user.IsModerator() {
+			return nil, errNoAccess
+		}
+	}
+
+	return s.comments.FindRevisions(commentID, pagination)
 }
 
 func (s *CommentService) DeleteComment(commentID int64, userID int64, postID int64) error {
-	var postAuthorId int64
-	err := s.db.QueryRow("SELECT author_id FROM posts WHERE id = ?", postID).Scan(&postAuthorId)
+	postAuthorId, err := s.posts.FindAuthorID(postID)
 	if err != nil {
 		return err
 	}
 
-	var commentAuthorId int64
-	err = s.db.QueryRow("SELECT author_id FROM comments WHERE id = ?", commentID).Scan(&commentAuthorId)
+	commentAuthorId, err := s.comments.FindAuthorID(commentID)
 	if err != nil {
 		return err
 	}
 
-	if userID == postAuthorId || userID == commentAuthorId {
-		_, err = s.db.Exec("DELETE FROM comments WHERE JSON_EXTRACT(post, '$.id') = ? AND id = ?", postID, commentID)
-		if err != nil {
-			return errInternalServer
-		}
-	} else {
+	if userID != postAuthorId && userID != commentAuthorId {
 		return errNoAccess
 	}
 
+	if err := s.comments.Delete(commentID, postID); err != nil {
+		return errInternalServer
+	}
+
 	return nil
 }
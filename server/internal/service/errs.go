@@ -10,4 +10,46 @@ var (
 	errPostNotFound				error = errors.New("post not found")
 	errNoAccess						error = errors.New("you have no access")
 	errTokenHasExpired    error = errors.New("token has expired")
+	errCommentNotFound    error = errors.New("comment not found")
+	errEditWindowExpired  error = errors.New("comment can no longer be edited")
+	errRevisionNotFound   error = errors.New("revision not found")
+	errInvalidPublishAt   error = errors.New("publish_at must be in the future for scheduled posts")
+	errEmptySearchQuery   error = errors.New("search query is empty")
+	errInvalidRefreshToken error = errors.New("refresh token is not valid")
+	errRefreshTokenReused error = errors.New("refresh token was already used, the session has been revoked")
+	errSessionRevoked     error = errors.New("session has been revoked")
+	errSessionNotFound    error = errors.New("session not found")
+	errFollowSelf         error = errors.New("you cannot follow yourself")
+	errCollectionNotFound error = errors.New("collection not found")
+	errCollectionExists   error = errors.New("a collection with this name already exists")
+	errUnknownReaction    error = errors.New("unknown reaction")
+	errInvalidAccessToken error = errors.New("access token is not valid")
+	errAccessTokenExpired error = errors.New("access token has expired")
+	errAccessTokenNotFound error = errors.New("access token not found")
+	errUnknownScope       error = errors.New("unknown scope")
+	errTwoFactorEnabled   error = errors.New("two-factor authentication is already enabled")
+	errTwoFactorNotEnrolled error = errors.New("two-factor authentication is not set up")
+	errInvalidTwoFactorCode error = errors.New("invalid two-factor authentication code")
+	errInvalidChallenge   error = errors.New("sign-in challenge is not valid or has expired")
+	errTwoFactorLocked    error = errors.New("too many wrong two-factor authentication codes, try again later")
+	errInvalidTag         error = errors.New("tags must be 1-32 characters of letters, digits, '-' or '_'")
 )
+
+// IsNotFound reports whether err means that the requested resource does not
+// exist, or does not belong to the user asking for it.
+func IsNotFound(err error) bool {
+	for _, notFound := range []error{
+		errUserNotFound,
+		errPostNotFound,
+		errCommentNotFound,
+		errRevisionNotFound,
+		errCollectionNotFound,
+		errAccessTokenNotFound,
+		errSessionNotFound,
+	} {
+		if errors.Is(err, notFound) {
+			return true
+		}
+	}
+	return false
+}
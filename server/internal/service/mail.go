@@ -1,15 +1,13 @@
 package service
 
 import (
-	"database/sql"
 	"fmt"
 	"net/smtp"
-	"os"
+
+	"github.com/morf1lo/blog-app/internal/config"
 )
 
 type MailService struct {
-	db *sql.DB
-
 	from string
 	pass string
 
@@ -17,13 +15,12 @@ type MailService struct {
 	port string
 }
 
-func NewMailService(db *sql.DB) *MailService {
+func NewMailService(cfg config.MailConfig) *MailService {
 	return &MailService{
-		db: db,
-		from: os.Getenv("EMAIL"),
-		pass: os.Getenv("EMAIL_PASSWORD"),
-		host: os.Getenv("SMTP_HOST"),
-		port: os.Getenv("SMTP_PORT"),
+		from: cfg.From,
+		pass: cfg.Password,
+		host: cfg.Host,
+		port: cfg.Port,
 	}
 }
 
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"log"
+	"regexp"
+
+	"github.com/morf1lo/blog-app/internal/models"
+	"github.com/morf1lo/blog-app/internal/repository"
+)
+
+// Mentions look like @username; at most maxMentions users are notified per text
+var mentionRegexp = regexp.MustCompile(`(?:^|[^\w@])@(\w{3,16})\b`)
+
+const maxMentions = 10
+
+type NotificationService struct {
+	notifications repository.Notification
+	users         repository.User
+}
+
+func NewNotificationService(notifications repository.Notification, users repository.User) *NotificationService {
+	return &NotificationService{notifications: notifications, users: users}
+}
+
+func (s *NotificationService) FindNotifications(userID int64, pagination models.Pagination) (*models.Page[models.Notification], error) {
+	return s.notifications.FindByUser(userID, pagination)
+}
+
+func (s *NotificationService) CountUnreadNotifications(userID int64) (int64, error) {
+	return s.notifications.CountUnread(userID)
+}
+
+func (s *NotificationService) MarkNotificationRead(notificationID int64, userID int64) error {
+	return s.notifications.MarkRead(notificationID, userID, now())
+}
+
+func (s *NotificationService) MarkAllNotificationsRead(userID int64) error {
+	return s.notifications.MarkAllRead(userID, now())
+}
+
+// notify records a notification for userID. Failing to notify must not fail
+// the action that caused it, so errors are only logged.
+func (s *NotificationService) notify(userID int64, kind models.NotificationKind, actorID int64, postID *int64, commentID *int64) {
+	if userID == actorID || userID == 0 {
+		return
+	}
+
+	err := s.notifications.Record(models.Notification{
+		UserID: userID,
+		Kind: kind,
+		Actor: models.NotificationActor{ID: actorID},
+		PostID: postID,
+		CommentID: commentID,
+		CreatedAt: now(),
+	})
+	if err != nil {
+		log.Printf("notify user %d of %s: %s", userID, kind, err)
+	}
+}
+
+// notifyMentions notifies the users mentioned in text, except those in skip,
+// who were already notified about the same post or comment.
+func (s *NotificationService) notifyMentions(text string, actorID int64, postID int64, commentID *int64, skip ...int64) {
+	notified := make(map[int64]bool, len(skip))
+	for _, userID := range skip {
+		notified[userID] = true
+	}
+
+	mentioned := make(map[string]bool)
+	for _, match := range mentionRegexp.FindAllStringSubmatch(text, -1) {
+		username := match[1]
+		if mentioned[username] {
+			continue
+		}
+		if len(mentioned) == maxMentions {
+			break
+		}
+		mentioned[username] = true
+
+		user, err := s.users.FindByUsername(username)
+		if err != nil || notified[user.ID] {
+			continue
+		}
+		notified[user.ID] = true
+
+		s.notify(user.ID, models.NotificationMention, actorID, &postID, commentID)
+	}
+}
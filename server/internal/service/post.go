@@ -2,216 +2,256 @@ package service
 
 import (
 	"database/sql"
+	"errors"
+	"strings"
 
 	"github.com/morf1lo/blog-app/internal/models"
+	"github.com/morf1lo/blog-app/internal/repository"
+	"github.com/morf1lo/blog-app/internal/utils/markdown"
 )
 
 type PostService struct {
-	db *sql.DB
+	posts     repository.Post
+	revisions repository.Revision
+	tags      repository.Tag
+	likes     repository.Like
+	search    repository.Search
+	annotator     *PostAnnotator
+	notifications *NotificationService
 }
 
-func NewPostService(db *sql.DB) *PostService {
-	return &PostService{db: db}
+func NewPostService(posts repository.Post, revisions repository.Revision, tags repository.Tag, likes repository.Like, search repository.Search, annotator *PostAnnotator, notifications *NotificationService) *PostService {
+	return &PostService{posts: posts, revisions: revisions, tags: tags, likes: likes, search: search, annotator: annotator, notifications: notifications}
 }
 
 func (s *PostService) CreatePost(post models.Post) error {
-	_, err := s.db.Exec("INSERT INTO posts(author_id, title, text) VALUES(?, ?, ?)", post.AuthorID, post.Title, post.Text)
+	html, err := markdown.Render(post.Text)
 	if err != nil {
-		return errInternalServer
+		return err
 	}
-	return nil
-}
 
-func (s *PostService) FindPostById(postID int64) (*models.Post, error) {
-	var post models.Post
-	err := s.db.QueryRow("SELECT id, author_id, title, text, likes FROM posts WHERE id = ?", postID).Scan(&post.ID, &post.AuthorID, &post.Title, &post.Text, &post.Likes)
+	post.HTML = html
+	post.CreatedAt = now()
+
+	if post.Status == "" {
+		post.Status = models.PostStatusPublished
+	}
+	publishAt, err := resolvePublishAt(post.Status, post.PublishAt, post.CreatedAt)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	err = s.db.QueryRow("SELECT username FROM users WHERE id = ?", post.AuthorID).Scan(&post.AuthorUsername)
+	post.PublishAt = publishAt
+
+	tags, err := normalizeTags(post.Tags)
 	if err != nil {
-		return nil, err
+		return err
+	}
+
+	postID, err := s.posts.Create(post)
+	if err != nil {
+		return errInternalServer
+	}
+
+	if len(tags) > 0 {
+		if err := s.tags.SetPostTags(postID, tags); err != nil {
+			return err
+		}
 	}
 
-	return &post, nil
+	if err := s.reindexPost(postID); err != nil {
+		return err
+	}
+
+	// Mentions in drafts and scheduled posts are announced once they are published
+	if post.Status == models.PostStatusPublished {
+		post.ID = postID
+		s.postPublished(&post)
+	}
+
+	return nil
 }
 
-func (s *PostService) FindAuthorPosts(authorID int64) (*[]models.Post, error) {
-	rows, err := s.db.Query("SELECT * FROM posts WHERE author_id = ?", authorID)
+func (s *PostService) FindPostById(postID int64, viewerID int64) (*models.Post, error) {
+	post, err := s.findVisiblePost(postID, viewerID)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
 
-	var posts []models.Post
-	for rows.Next() {
-		var post models.Post
-		if err := rows.Scan(&post.ID, &post.AuthorID, &post.Title, &post.Text, &post.Likes); err != nil {
-			return nil, errInternalServer
-		}
-		if err := s.db.QueryRow("SELECT username FROM users WHERE id = ?", authorID).Scan(&post.AuthorUsername); err != nil {
+	// Posts written before Markdown support have no stored rendering
+	if post.HTML == "" && post.Text != "" {
+		if post.HTML, err = markdown.Render(post.Text); err != nil {
 			return nil, err
 		}
-
-		posts = append(posts, post)
 	}
 
-	if err := rows.Err(); err != nil {
+	posts := []models.Post{*post}
+	if err := s.annotator.Annotate(posts, viewerID); err != nil {
 		return nil, err
 	}
 
-	return &posts, nil
+	return &posts[0], nil
 }
 
-func (s *PostService) UpdatePost(updateOpts models.PostUpdateOptions, postID int64, userID int64) error {
-	updQuery, values := updateOpts.FilterUpdateOptions()
-	if updQuery == "" {
-		return nil
-	}
+func (s *PostService) FindAuthorPosts(authorID int64, viewerID int64, pagination models.Pagination) (*models.Page[models.Post], error) {
+	return s.annotator.page(viewerID)(s.posts.FindByAuthor(authorID, pagination))
+}
 
-	updQuery += " WHERE id = ? AND author_id = ?"
-	values = append(values, postID, userID)
+func (s *PostService) FindFeed(userID int64, pagination models.Pagination) (*models.Page[models.Post], error) {
+	return s.annotator.page(userID)(s.posts.FindFeed(userID, pagination))
+}
 
-	_, err := s.db.Exec(updQuery, values...)
+func (s *PostService) UpdatePost(updateOpts models.PostUpdateOptions, postID int64, userID int64) error {
+	post, err := s.findOwnPost(postID, userID)
 	if err != nil {
 		return err
 	}
-	return nil
-}
 
-func (s *PostService) LikePost(postID int64, userID int64) error {
-	// Checking post existence
-	var exists bool
-	err := s.db.QueryRow("SELECT EXISTS(SELECT 1 FROM posts WHERE id = ?)", postID).Scan(&exists)
-	if err != nil {
-		return err
+	// Archived posts were live before, so only drafts and scheduled posts are announced
+	becamePublished := updateOpts.Status == models.PostStatusPublished &&
+		(post.Status == models.PostStatusDraft || post.Status == models.PostStatusScheduled)
+
+	if updateOpts.Tags != nil {
+		tags, err := normalizeTags(*updateOpts.Tags)
+		if err != nil {
+			return err
+		}
+		updateOpts.Tags = &tags
 	}
-	if !exists {
-		return errPostNotFound
+
+	if updQuery, _ := updateOpts.FilterUpdateOptions(); updQuery == "" {
+		return s.updateTags(postID, updateOpts.Tags)
 	}
 
-	// Checking if user has already likes a post
-	var alreadyLiked bool
-	err = s.db.QueryRow("SELECT EXISTS(SELECT 1 FROM likes WHERE user_id = ? AND post_id = ?)", userID, postID).Scan(&alreadyLiked)
+	html, err := markdown.Render(strings.TrimSpace(updateOpts.Text))
 	if err != nil {
 		return err
 	}
+	updateOpts.HTML = html
 
-	if alreadyLiked {
-		_, err := s.db.Exec("UPDATE posts SET likes = likes - 1 WHERE id = ?", postID)
-		if err != nil {
-			return errInternalServer
-		}
+	updatedAt := now()
 
-		_, err = s.db.Exec("DELETE FROM likes WHERE user_id = ? AND post_id = ?", userID, postID)
+	if updateOpts.Status == models.PostStatusPublished && post.Status == models.PostStatusPublished {
+		// Re-sending the status keeps the time the post went live
+		updateOpts.PublishAt = post.PublishAt
+	} else if updateOpts.Status != "" {
+		publishAt, err := resolvePublishAt(updateOpts.Status, updateOpts.PublishAt, updatedAt)
 		if err != nil {
-			return errInternalServer
+			return err
 		}
-	} else {
-		_, err := s.db.Exec("UPDATE posts SET likes = likes + 1 WHERE id = ?", postID)
-		if err != nil {
-			return errInternalServer
+		updateOpts.PublishAt = publishAt
+	}
+
+	if err := s.posts.Update(updateOpts, postID, userID, updatedAt); err != nil {
+		return err
+	}
+
+	if err := s.updateTags(postID, updateOpts.Tags); err != nil {
+		return err
+	}
+
+	if updateOpts.ChangesContent() {
+		if err := s.reindexPost(postID); err != nil {
+			return err
 		}
+	}
 
-		_, err = s.db.Exec("INSERT INTO likes(user_id, post_id) VALUES(?, ?)", userID, postID)
+	if becamePublished {
+		published, err := s.posts.FindByID(postID)
 		if err != nil {
-			return errInternalServer
+			return err
 		}
+		s.postPublished(published)
 	}
 
 	return nil
 }
 
-func (s *PostService) DeletePost(postID int64, userID int64) error {
-	if err := deletePostDataFromDB(s.db, postID, userID); err != nil {
+// reindexPost refreshes the search index entries of a post whose content
+// changed. Its revision is recorded by the post repository along with the change.
+func (s *PostService) reindexPost(postID int64) error {
+	post, err := s.posts.FindByID(postID)
+	if err != nil {
 		return err
 	}
-	return nil
+
+	return indexPost(s.search, post)
+}
+
+func (s *PostService) updateTags(postID int64, tags *[]string) error {
+	if tags == nil {
+		return nil
+	}
+	return s.tags.SetPostTags(postID, *tags)
 }
 
-func deletePostDataFromDB(db *sql.DB, postID int64, userID int64) error {
-	tx, err := db.Begin()
+func (s *PostService) checkAuthor(postID int64, userID int64) error {
+	authorID, err := s.posts.FindAuthorID(postID)
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return errPostNotFound
+		}
 		return err
 	}
-	defer tx.Rollback()
 
-	queries := [2]string{
-		"DELETE FROM posts WHERE id = ? AND author_id = ?",
-		"DELETE FROM comments WHERE JSON_EXTRACT(post, '$.id') = ? AND JSON_EXTRACT(post, '$.author') = ?",
+	if authorID != userID {
+		return errNoAccess
 	}
 
-	for i, query := range queries {
-		if i == 0 {
-			_, err := tx.Exec(query, postID, userID)
-			if err != nil {
-				return err
-			}
-		} else {
-			_, err := tx.Exec(query, postID, userID)
-			if err != nil {
-				return err
-			}
-		}
+	return nil
+}
+
+// LikePost toggles the user's like on a post and returns whether the post is liked afterwards.
+func (s *PostService) LikePost(postID int64, userID int64) (bool, error) {
+	// Checking post existence
+	post, err := s.findVisiblePost(postID, userID)
+	if err != nil {
+		return false, err
 	}
 
-	_, err = tx.Exec("DELETE FROM likes WHERE post_id = ?", postID)
+	liked, err := s.likes.Toggle(userID, postID)
 	if err != nil {
-		return err
+		return false, errInternalServer
+	}
+
+	if liked {
+		s.notifications.notify(post.AuthorID, models.NotificationLike, userID, &postID, nil)
 	}
 
-	return tx.Commit()
+	return liked, nil
 }
 
-func (s *PostService) FindUserLikes(userID int64) (*[]models.Post, error) {
-	var postIDs []int64
-	rows, err := s.db.Query("SELECT post_id FROM likes WHERE user_id = ?", userID)
+func (s *PostService) AddLike(postID int64, userID int64) error {
+	post, err := s.findVisiblePost(postID, userID)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	defer rows.Close()
 
-	for rows.Next() {
-		var postID int64
-		if err := rows.Scan(&postID); err != nil {
-			return nil, err
-		}
-		postIDs = append(postIDs, postID)
+	added, err := s.likes.Add(userID, postID)
+	if err != nil {
+		return errInternalServer
 	}
 
-	var posts []models.Post
-	for _, postID := range postIDs {
-		var post models.Post
-		err := s.db.QueryRow("SELECT id, author_id, title, text, likes FROM posts WHERE id = ?", postID).Scan(&post.ID, &post.AuthorID, &post.Title, &post.Text, &post.Likes)
-		if err != nil {
-			return nil, err
-		}
-
-		posts = append(posts, post)
+	if added {
+		s.notifications.notify(post.AuthorID, models.NotificationLike, userID, &postID, nil)
 	}
 
-	return &posts, nil
+	return nil
 }
 
-func (s *PostService) SearchPosts(query string) (*[]models.Post, error) {
-	rows, err := s.db.Query("SELECT id, title, likes FROM posts WHERE title LIKE ? ORDER BY likes DESC LIMIT 15", "%" + query + "%")
-	if err != nil {
-		return nil, err
+// RemoveLike skips the visibility check so that likes on posts that were
+// since archived can still be taken back.
+func (s *PostService) RemoveLike(postID int64, userID int64) error {
+	if _, err := s.likes.Remove(userID, postID); err != nil {
+		return errInternalServer
 	}
-	defer rows.Close()
 
-	var posts []models.Post
-	for rows.Next() {
-		var post models.Post
-		if err := rows.Scan(&post.ID, &post.Title, &post.Likes); err != nil {
-			return nil, err
-		}
-		posts = append(posts, post)
-	}
+	return nil
+}
 
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
+func (s *PostService) DeletePost(postID int64, userID int64) error {
+	return s.posts.Delete(postID, userID)
+}
 
-	return &posts, nil
+func (s *PostService) FindUserLikes(userID int64, pagination models.Pagination) (*models.Page[models.Post], error) {
+	return s.annotator.page(userID)(s.likes.FindLikedPosts(userID, pagination))
 }
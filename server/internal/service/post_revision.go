@@ -0,0 +1,75 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/morf1lo/blog-app/internal/models"
+	"github.com/morf1lo/blog-app/internal/utils/diff"
+	"github.com/morf1lo/blog-app/internal/utils/markdown"
+)
+
+func (s *PostService) FindPostRevisions(postID int64, viewerID int64, pagination models.Pagination) (*models.Page[models.PostRevision], error) {
+	if _, err := s.findVisiblePost(postID, viewerID); err != nil {
+		return nil, err
+	}
+
+	return s.revisions.FindByPost(postID, pagination)
+}
+
+func (s *PostService) findRevision(postID int64, revisionNumber int64) (*models.PostRevision, error) {
+	revision, err := s.revisions.Find(postID, revisionNumber)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errRevisionNotFound
+		}
+		return nil, err
+	}
+	return revision, nil
+}
+
+func (s *PostService) DiffPostRevisions(postID int64, viewerID int64, from int64, to int64) (*models.PostRevisionDiff, error) {
+	if _, err := s.findVisiblePost(postID, viewerID); err != nil {
+		return nil, err
+	}
+
+	fromRevision, err := s.findRevision(postID, from)
+	if err != nil {
+		return nil, err
+	}
+
+	toRevision, err := s.findRevision(postID, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.PostRevisionDiff{
+		From: from,
+		To: to,
+		Title: diff.Lines(fromRevision.Title, toRevision.Title),
+		Text: diff.Lines(fromRevision.Text, toRevision.Text),
+	}, nil
+}
+
+func (s *PostService) RestorePostRevision(postID int64, revisionNumber int64, userID int64) error {
+	if err := s.checkAuthor(postID, userID); err != nil {
+		return err
+	}
+
+	revision, err := s.findRevision(postID, revisionNumber)
+	if err != nil {
+		return err
+	}
+
+	html, err := markdown.Render(revision.Text)
+	if err != nil {
+		return err
+	}
+
+	restoreOpts := models.PostUpdateOptions{Title: revision.Title, Text: revision.Text, HTML: html}
+	if err := s.posts.Update(restoreOpts, postID, userID, now()); err != nil {
+		return err
+	}
+
+	return s.reindexPost(postID)
+}
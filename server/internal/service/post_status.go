@@ -0,0 +1,90 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/morf1lo/blog-app/internal/models"
+)
+
+// resolvePublishAt returns the publish time stored alongside a post status:
+// the requested future time for scheduled posts, now for posts being published and nothing otherwise.
+func resolvePublishAt(status models.PostStatus, requested *time.Time, now time.Time) (*time.Time, error) {
+	switch status {
+	case models.PostStatusScheduled:
+		if requested == nil || !requested.After(now) {
+			return nil, errInvalidPublishAt
+		}
+		publishAt := requested.UTC().Truncate(time.Second)
+		return &publishAt, nil
+	case models.PostStatusPublished:
+		return &now, nil
+	default:
+		return nil, nil
+	}
+}
+
+// findVisiblePost hides drafts, scheduled and archived posts from everyone but their author.
+func (s *PostService) findVisiblePost(postID int64, viewerID int64) (*models.Post, error) {
+	post, err := s.posts.FindByID(postID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errPostNotFound
+		}
+		return nil, err
+	}
+
+	if !post.VisibleTo(viewerID) {
+		return nil, errPostNotFound
+	}
+
+	return post, nil
+}
+
+func (s *PostService) FindUserPostsByStatus(userID int64, status models.PostStatus, pagination models.Pagination) (*models.Page[models.Post], error) {
+	return s.annotator.page(userID)(s.posts.FindByAuthorAndStatus(userID, status, pagination))
+}
+
+// PublishScheduledPosts publishes the scheduled posts that are due and returns how many went live.
+func (s *PostService) PublishScheduledPosts() (int64, error) {
+	postIDs, err := s.posts.PublishDue(now())
+	if err != nil {
+		return 0, err
+	}
+
+	posts, err := s.posts.FindByIDs(postIDs)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range posts {
+		s.postPublished(&posts[i])
+	}
+
+	return int64(len(postIDs)), nil
+}
+
+// postPublished runs when a post goes live, whether it was published when it
+// was written, by the scheduler or by an edit. The search index covers posts of
+// every status and the feed is queried live, so only mentions are left to announce.
+func (s *PostService) postPublished(post *models.Post) {
+	s.notifications.notifyMentions(post.Title+"\n"+post.Text, post.AuthorID, post.ID, nil)
+}
+
+// findOwnPost returns a post for its author to change.
+func (s *PostService) findOwnPost(postID int64, userID int64) (*models.Post, error) {
+	post, err := s.posts.FindByID(postID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errPostNotFound
+		}
+		return nil, err
+	}
+
+	if post.AuthorID != userID {
+		return nil, errNoAccess
+	}
+
+	return post, nil
+}
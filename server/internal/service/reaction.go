@@ -0,0 +1,186 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/morf1lo/blog-app/internal/config"
+	"github.com/morf1lo/blog-app/internal/models"
+	"github.com/morf1lo/blog-app/internal/repository"
+)
+
+type ReactionService struct {
+	reactions repository.Reaction
+	posts     repository.Post
+	comments  repository.Comment
+	available []config.Reaction
+}
+
+func NewReactionService(reactions repository.Reaction, posts repository.Post, comments repository.Comment, available []config.Reaction) *ReactionService {
+	return &ReactionService{reactions: reactions, posts: posts, comments: comments, available: available}
+}
+
+func (s *ReactionService) ListReactions() []models.ReactionKind {
+	reactions := make([]models.ReactionKind, len(s.available))
+	for i, reaction := range s.available {
+		reactions[i] = models.ReactionKind{Name: reaction.Name, Emoji: reaction.Emoji}
+	}
+	return reactions
+}
+
+func (s *ReactionService) ReactToPost(postID int64, userID int64, reaction string) error {
+	if err := s.checkReactable(postID, 0, userID, reaction); err != nil {
+		return err
+	}
+	return s.reactions.Add(userID, models.ReactionTargetPost, postID, reaction)
+}
+
+func (s *ReactionService) UnreactToPost(postID int64, userID int64, reaction string) error {
+	if err := s.checkReactable(postID, 0, userID, reaction); err != nil {
+		return err
+	}
+	return s.reactions.Remove(userID, models.ReactionTargetPost, postID, reaction)
+}
+
+func (s *ReactionService) ReactToComment(postID int64, commentID int64, userID int64, reaction string) error {
+	if err := s.checkReactable(postID, commentID, userID, reaction); err != nil {
+		return err
+	}
+	return s.reactions.Add(userID, models.ReactionTargetComment, commentID, reaction)
+}
+
+func (s *ReactionService) UnreactToComment(postID int64, commentID int64, userID int64, reaction string) error {
+	if err := s.checkReactable(postID, commentID, userID, reaction); err != nil {
+		return err
+	}
+	return s.reactions.Remove(userID, models.ReactionTargetComment, commentID, reaction)
+}
+
+// checkReactable makes sure the reaction is configured and the post (and the
+// comment, when commentID is set) can be seen by the user.
+func (s *ReactionService) checkReactable(postID int64, commentID int64, userID int64, reaction string) error {
+	if _, ok := s.find(reaction); !ok {
+		return errUnknownReaction
+	}
+
+	post, err := s.posts.FindByID(postID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return errPostNotFound
+		}
+		return err
+	}
+	if !post.VisibleTo(userID) {
+		return errPostNotFound
+	}
+
+	if commentID == 0 {
+		return nil
+	}
+
+	comment, err := s.comments.FindByID(commentID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return errCommentNotFound
+		}
+		return err
+	}
+	if comment.Post.ID != postID || comment.Deleted {
+		return errCommentNotFound
+	}
+
+	return nil
+}
+
+func (s *ReactionService) find(name string) (config.Reaction, bool) {
+	for _, reaction := range s.available {
+		if reaction.Name == name {
+			return reaction, true
+		}
+	}
+	return config.Reaction{}, false
+}
+
+// AttachToPosts fills in the reaction counts of the posts and the reactions left by the viewer.
+func (s *ReactionService) AttachToPosts(posts []models.Post, viewerID int64) error {
+	postIDs := make([]int64, len(posts))
+	for i := range posts {
+		postIDs[i] = posts[i].ID
+	}
+
+	counts, mine, err := s.tally(models.ReactionTargetPost, postIDs, viewerID)
+	if err != nil {
+		return err
+	}
+
+	for i := range posts {
+		posts[i].Reactions = counts[posts[i].ID]
+		posts[i].MyReactions = mine[posts[i].ID]
+	}
+	return nil
+}
+
+// AttachToComments does the same as AttachToPosts for comments and all of their loaded replies.
+func (s *ReactionService) AttachToComments(comments []models.Comment, viewerID int64) error {
+	var all []*models.Comment
+	var walk func(comments []models.Comment)
+	walk = func(comments []models.Comment) {
+		for i := range comments {
+			all = append(all, &comments[i])
+			walk(comments[i].Replies)
+		}
+	}
+	walk(comments)
+
+	commentIDs := make([]int64, len(all))
+	for i, comment := range all {
+		commentIDs[i] = comment.ID
+	}
+
+	counts, mine, err := s.tally(models.ReactionTargetComment, commentIDs, viewerID)
+	if err != nil {
+		return err
+	}
+
+	for _, comment := range all {
+		comment.Reactions = counts[comment.ID]
+		comment.MyReactions = mine[comment.ID]
+	}
+	return nil
+}
+
+// tally groups reaction counts by target in the configured order. Reactions
+// that are no longer configured are left out.
+func (s *ReactionService) tally(targetType models.ReactionTarget, targetIDs []int64, viewerID int64) (map[int64][]models.ReactionCount, map[int64][]string, error) {
+	tallies, err := s.reactions.Tally(targetType, targetIDs, viewerID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	byTarget := make(map[int64]map[string]models.ReactionTally, len(targetIDs))
+	for _, tally := range tallies {
+		if byTarget[tally.TargetID] == nil {
+			byTarget[tally.TargetID] = make(map[string]models.ReactionTally)
+		}
+		byTarget[tally.TargetID][tally.Name] = tally
+	}
+
+	counts := make(map[int64][]models.ReactionCount, len(targetIDs))
+	mine := make(map[int64][]string, len(targetIDs))
+	for _, targetID := range targetIDs {
+		counts[targetID] = []models.ReactionCount{}
+		mine[targetID] = []string{}
+		for _, reaction := range s.available {
+			tally, ok := byTarget[targetID][reaction.Name]
+			if !ok {
+				continue
+			}
+			counts[targetID] = append(counts[targetID], models.ReactionCount{Name: reaction.Name, Emoji: reaction.Emoji, Count: tally.Count})
+			if tally.Mine {
+				mine[targetID] = append(mine[targetID], reaction.Name)
+			}
+		}
+	}
+
+	return counts, mine, nil
+}
@@ -0,0 +1,306 @@
+package service
+
+import (
+	"math"
+
+	"github.com/morf1lo/blog-app/internal/models"
+	"github.com/morf1lo/blog-app/internal/repository"
+	"github.com/morf1lo/blog-app/internal/utils/search"
+)
+
+const snippetWidth = 200
+
+// maxPrefixTerms caps how many indexed terms a prefix query such as "prog*" expands to.
+const maxPrefixTerms = 50
+
+type SearchService struct {
+	search    repository.Search
+	posts     repository.Post
+	annotator *PostAnnotator
+}
+
+func NewSearchService(search repository.Search, posts repository.Post, annotator *PostAnnotator) *SearchService {
+	return &SearchService{search: search, posts: posts, annotator: annotator}
+}
+
+func indexPost(index repository.Search, post *models.Post) error {
+	sources := map[models.SearchSource]string{
+		models.SearchSourceTitle: post.Title,
+		models.SearchSourceText: post.Text,
+		models.SearchSourceAuthor: post.AuthorUsername,
+	}
+
+	for source, text := range sources {
+		if err := index.IndexSource(post.ID, source, post.ID, terms(text)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func indexComment(index repository.Search, comment *models.Comment) error {
+	return index.IndexSource(comment.Post.ID, models.SearchSourceComment, comment.ID, terms(comment.Text))
+}
+
+func terms(text string) []string {
+	tokens := search.Tokenize(text)
+	terms := make([]string, len(tokens))
+	for i, token := range tokens {
+		terms[i] = token.Term
+	}
+	return terms
+}
+
+// IndexMissing indexes posts and comments written before the search index
+// existed and returns how many of them were indexed.
+func (s *SearchService) IndexMissing() (int, error) {
+	postIDs, err := s.search.FindUnindexedPosts()
+	if err != nil {
+		return 0, err
+	}
+
+	posts, err := s.posts.FindByIDs(postIDs)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range posts {
+		if err := indexPost(s.search, &posts[i]); err != nil {
+			return 0, err
+		}
+	}
+
+	comments, err := s.search.FindUnindexedComments()
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range comments {
+		if err := indexComment(s.search, &comments[i]); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(posts) + len(comments), nil
+}
+
+type sourceKey struct {
+	source   models.SearchSource
+	sourceID int64
+}
+
+// SearchPosts returns published posts matching every clause of the query,
+// ordered by relevance (or the requested sort) with highlighted titles and snippets.
+// Pages are addressed by offset, since relevance scores are not stable keys.
+func (s *SearchService) SearchPosts(query string, tags []string, viewerID int64, pagination models.Pagination) (*models.Page[models.SearchResult], error) {
+	clauses := search.ParseQuery(query)
+	if len(clauses) == 0 {
+		return nil, errEmptySearchQuery
+	}
+
+	tags, err := normalizeTags(tags)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &models.Page[models.SearchResult]{Items: []models.SearchResult{}}
+
+	searchQuery := models.SearchQuery{
+		Tags: tags,
+		Sort: pagination.Sort,
+		Limit: pagination.Limit + 1,
+	}
+	if pagination.Cursor != nil {
+		searchQuery.Offset = int(pagination.Cursor.Value)
+	}
+
+	var terms []string
+	for _, clause := range clauses {
+		searchClause := models.SearchClause{Terms: clause.Terms, Phrase: clause.IsPhrase()}
+		if clause.Prefix {
+			searchClause.Terms, err = s.search.ExpandPrefix(clause.Terms[0], maxPrefixTerms)
+			if err != nil {
+				return nil, err
+			}
+			if len(searchClause.Terms) == 0 {
+				return page, nil
+			}
+		}
+		searchQuery.Clauses = append(searchQuery.Clauses, searchClause)
+		terms = append(terms, searchClause.Terms...)
+	}
+
+	matches, err := s.search.FindMatches(searchQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(matches) > pagination.Limit {
+		matches = matches[:pagination.Limit]
+		page.NextCursor = models.Cursor{Sort: pagination.Sort, Value: int64(searchQuery.Offset + pagination.Limit)}.Encode()
+	}
+
+	page.Items, err = s.loadResults(clauses, terms, matches, viewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	return page, nil
+}
+
+// matchedSources returns, for every post, the sources in which at least one
+// clause of the query occurs.
+func matchedSources(clauses []search.Clause, postings []models.SearchPosting) map[int64]map[sourceKey]bool {
+	positions := make(map[int64]map[sourceKey]map[int]string)
+	for _, posting := range postings {
+		sources, ok := positions[posting.PostID]
+		if !ok {
+			sources = make(map[sourceKey]map[int]string)
+			positions[posting.PostID] = sources
+		}
+
+		key := sourceKey{posting.Source, posting.SourceID}
+		if sources[key] == nil {
+			sources[key] = make(map[int]string)
+		}
+		sources[key][posting.Position] = posting.Term
+	}
+
+	matched := make(map[int64]map[sourceKey]bool, len(positions))
+	for postID, sources := range positions {
+		matched[postID] = make(map[sourceKey]bool)
+		for key, sourcePositions := range sources {
+			for _, clause := range clauses {
+				if countClause(clause, sourcePositions) > 0 {
+					matched[postID][key] = true
+					break
+				}
+			}
+		}
+	}
+
+	return matched
+}
+
+func countClause(clause search.Clause, positions map[int]string) int {
+	count := 0
+	for position, term := range positions {
+		if !clause.IsPhrase() {
+			if clause.Matches(term) {
+				count++
+			}
+			continue
+		}
+
+		if term != clause.Terms[0] {
+			continue
+		}
+		phrase := true
+		for offset, next := range clause.Terms[1:] {
+			if positions[position+offset+1] != next {
+				phrase = false
+				break
+			}
+		}
+		if phrase {
+			count++
+		}
+	}
+	return count
+}
+
+// loadResults fetches the posts of a page of matches and highlights the query
+// terms in them. Only the postings of these posts are read, to pick the snippets.
+func (s *SearchService) loadResults(clauses []search.Clause, terms []string, matches []models.SearchMatch, viewerID int64) ([]models.SearchResult, error) {
+	postIDs := make([]int64, len(matches))
+	for i, match := range matches {
+		postIDs[i] = match.PostID
+	}
+
+	postings, err := s.search.FindPostings(postIDs, terms)
+	if err != nil {
+		return nil, err
+	}
+	matched := matchedSources(clauses, postings)
+
+	var commentIDs []int64
+	for _, postID := range postIDs {
+		if commentID, ok := snippetComment(postID, matched[postID]); ok {
+			commentIDs = append(commentIDs, commentID)
+		}
+	}
+
+	posts, err := s.posts.FindByIDs(postIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.annotator.Annotate(posts, viewerID); err != nil {
+		return nil, err
+	}
+
+	commentTexts, err := s.search.FindCommentTexts(commentIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	postsByID := make(map[int64]models.Post, len(posts))
+	for _, post := range posts {
+		postsByID[post.ID] = post
+	}
+
+	highlight := func(term string) bool {
+		for _, clause := range clauses {
+			if !clause.IsPhrase() && clause.Matches(term) {
+				return true
+			}
+			for _, phraseTerm := range clause.Terms {
+				if clause.IsPhrase() && phraseTerm == term {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	results := make([]models.SearchResult, 0, len(matches))
+	for _, match := range matches {
+		post, ok := postsByID[match.PostID]
+		if !ok {
+			continue
+		}
+
+		snippetSource := post.Text
+		if commentID, ok := snippetComment(post.ID, matched[post.ID]); ok {
+			snippetSource = commentTexts[commentID]
+		}
+
+		results = append(results, models.SearchResult{
+			Post: post,
+			Score: math.Round(match.Score*1000) / 1000,
+			Highlight: models.SearchHighlight{
+				Title: search.Highlight(post.Title, 0, highlight),
+				Snippet: search.Highlight(snippetSource, snippetWidth, highlight),
+			},
+		})
+	}
+
+	return results, nil
+}
+
+// snippetComment returns the comment to quote when the query matched a
+// post's comments but not its text.
+func snippetComment(postID int64, matched map[sourceKey]bool) (int64, bool) {
+	if matched[sourceKey{models.SearchSourceText, postID}] {
+		return 0, false
+	}
+
+	var commentID int64
+	for key := range matched {
+		if key.source == models.SearchSourceComment && (commentID == 0 || key.sourceID < commentID) {
+			commentID = key.sourceID
+		}
+	}
+	return commentID, commentID != 0
+}
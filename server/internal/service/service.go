@@ -1,13 +1,14 @@
 package service
 
 import (
-	"database/sql"
 	"mime/multipart"
 	"time"
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/morf1lo/blog-app/internal/config"
 	"github.com/morf1lo/blog-app/internal/models"
+	"github.com/morf1lo/blog-app/internal/repository"
 )
 
 type Mail interface {
@@ -21,6 +22,29 @@ type Authorization interface {
 	SignIn(user models.User) (int64, error)
 	SaveResetToken(email string, token string, tokenExpiry time.Time) error
 	ResetPassword(token string, newPassword string) error
+	CreateSession(userID int64, userAgent string, ip string) (*models.SessionTokens, error)
+	RefreshSession(refreshToken string, ip string) (*models.SessionTokens, error)
+	Authenticate(accessToken string, ip string) (int64, int64, error)
+	ListSessions(userID int64, currentSessionID int64) ([]models.Session, error)
+	RevokeSession(sessionID int64, userID int64) error
+	RevokeOtherSessions(userID int64, currentSessionID int64) error
+}
+
+type AccessToken interface {
+	CreateAccessToken(userID int64, options models.AccessTokenOptions) (*models.AccessToken, error)
+	FindAccessTokens(userID int64) ([]models.AccessToken, error)
+	RevokeAccessToken(tokenID int64, userID int64) error
+	AuthenticateAccessToken(token string) (*models.AccessToken, error)
+}
+
+type TwoFactor interface {
+	FindTwoFactorStatus(userID int64) (*models.TwoFactorStatus, error)
+	IsTwoFactorEnabled(userID int64) (bool, error)
+	EnrollTwoFactor(userID int64) (*models.TwoFactorEnrollment, error)
+	ConfirmTwoFactor(userID int64, code string) error
+	DisableTwoFactor(userID int64, password string) error
+	CreateTwoFactorChallenge(userID int64) (string, time.Time, error)
+	VerifyTwoFactorChallenge(challenge string, code string) (int64, error)
 }
 
 type User interface {
@@ -28,42 +52,106 @@ type User interface {
 	FindUserById(userID int64) (*models.User, error)
 	FindUserByUsername(username string) (*models.User, error)
 	SetAvatar(c *gin.Context, file *multipart.FileHeader, userID int64) error
-	Follow(userID int64, followingID int64) error
-	FindUserFollowers(userID int64) (*[]models.User, error)
-	FindUserFollows(userID int64) (*[]models.User, error)
+	Follow(userID int64, followingID int64) (bool, error)
+	AddFollow(userID int64, followingID int64) error
+	RemoveFollow(userID int64, followingID int64) error
+	FindUserFollowers(userID int64, pagination models.Pagination) (*models.Page[models.User], error)
+	FindUserFollows(userID int64, pagination models.Pagination) (*models.Page[models.User], error)
 }
 
 type Post interface {
 	CreatePost(post models.Post) error
-	FindPostById(postID int64) (*models.Post, error)
-	FindAuthorPosts(authorID int64) (*[]models.Post, error)
+	FindPostById(postID int64, viewerID int64) (*models.Post, error)
+	FindAuthorPosts(authorID int64, viewerID int64, pagination models.Pagination) (*models.Page[models.Post], error)
+	FindFeed(userID int64, pagination models.Pagination) (*models.Page[models.Post], error)
+	FindUserPostsByStatus(userID int64, status models.PostStatus, pagination models.Pagination) (*models.Page[models.Post], error)
+	PublishScheduledPosts() (int64, error)
 	UpdatePost(updateOpts models.PostUpdateOptions, postID int64, userID int64) error
-	LikePost(postID int64, userID int64) error
+	LikePost(postID int64, userID int64) (bool, error)
+	AddLike(postID int64, userID int64) error
+	RemoveLike(postID int64, userID int64) error
 	DeletePost(postID int64, userID int64) error
-	FindUserLikes(userID int64) (*[]models.Post, error)
-	SearchPosts(query string) (*[]models.Post, error)
+	FindUserLikes(userID int64, pagination models.Pagination) (*models.Page[models.Post], error)
+	FindPostRevisions(postID int64, viewerID int64, pagination models.Pagination) (*models.Page[models.PostRevision], error)
+	DiffPostRevisions(postID int64, viewerID int64, from int64, to int64) (*models.PostRevisionDiff, error)
+	RestorePostRevision(postID int64, revision int64, userID int64) error
+}
+
+type Tag interface {
+	FindPopularTags(limit int) ([]models.TagCount, error)
+	FindTagPosts(tag string, viewerID int64, pagination models.Pagination) (*models.Page[models.Post], error)
+}
+
+type Search interface {
+	SearchPosts(query string, tags []string, viewerID int64, pagination models.Pagination) (*models.Page[models.SearchResult], error)
+	IndexMissing() (int, error)
+}
+
+type Reaction interface {
+	ListReactions() []models.ReactionKind
+	ReactToPost(postID int64, userID int64, reaction string) error
+	UnreactToPost(postID int64, userID int64, reaction string) error
+	ReactToComment(postID int64, commentID int64, userID int64, reaction string) error
+	UnreactToComment(postID int64, commentID int64, userID int64, reaction string) error
+}
+
+type Bookmark interface {
+	AddBookmark(postID int64, userID int64, collectionID *int64) error
+	RemoveBookmark(postID int64, userID int64) error
+	FindBookmarks(userID int64, collectionID *int64, pagination models.Pagination) (*models.Page[models.Post], error)
+	CreateBookmarkCollection(collection models.BookmarkCollection) (*models.BookmarkCollection, error)
+	FindBookmarkCollections(userID int64) ([]models.BookmarkCollection, error)
+	DeleteBookmarkCollection(collectionID int64, userID int64) error
+}
+
+type Notification interface {
+	FindNotifications(userID int64, pagination models.Pagination) (*models.Page[models.Notification], error)
+	CountUnreadNotifications(userID int64) (int64, error)
+	MarkNotificationRead(notificationID int64, userID int64) error
+	MarkAllNotificationsRead(userID int64) error
 }
 
 type Comment interface {
 	AddComment(comment models.Comment, userID int64, postID int64) error
-	FindAllPostComments(postID int64) (*[]models.Comment, error)
+	FindAllPostComments(postID int64, viewerID int64, pagination models.Pagination, tree models.CommentTreeOptions) (*models.Page[models.Comment], error)
+	FindCommentReplies(postID int64, commentID int64, viewerID int64, pagination models.Pagination, tree models.CommentTreeOptions) (*models.Page[models.Comment], error)
+	EditComment(commentID int64, userID int64, postID int64, text string) error
+	FindCommentRevisions(commentID int64, userID int64, postID int64, pagination models.Pagination) (*models.Page[models.CommentRevision], error)
 	DeleteComment(commentID int64, userID int64, postID int64) error
 }
 
 type Service struct {
 	Mail
 	Authorization
+	AccessToken
+	TwoFactor
 	User
 	Post
+	Tag
+	Search
+	Reaction
+	Bookmark
+	Notification
 	Comment
 }
 
-func NewService(db *sql.DB) *Service {
+func NewService(repos *repository.Repository, cfg *config.Config) *Service {
+	reactions := NewReactionService(repos.Reaction, repos.Post, repos.Comment, cfg.Reactions)
+	annotator := NewPostAnnotator(reactions, repos.Bookmark)
+	notifications := NewNotificationService(repos.Notification, repos.User)
+
 	return &Service{
-		Mail: NewMailService(db),
-		Authorization: NewAuthService(db),
-		User: NewUserService(db),
-		Post: NewPostService(db),
-		Comment: NewCommentService(db),
+		Mail: NewMailService(cfg.Mail),
+		Authorization: NewAuthService(repos.User, repos.Token, repos.Session, cfg.Auth),
+		AccessToken: NewAccessTokenService(repos.AccessToken),
+		TwoFactor: NewTwoFactorService(repos.TwoFactor, repos.User),
+		User: NewUserService(repos.User, repos.Follow, notifications, cfg.Server.URL),
+		Post: NewPostService(repos.Post, repos.Revision, repos.Tag, repos.Like, repos.Search, annotator, notifications),
+		Tag: NewTagService(repos.Tag, repos.Post, annotator),
+		Search: NewSearchService(repos.Search, repos.Post, annotator),
+		Reaction: reactions,
+		Bookmark: NewBookmarkService(repos.Bookmark, repos.Post, annotator),
+		Comment: NewCommentService(repos.Comment, repos.Post, repos.User, repos.Search, reactions, notifications, cfg.Comments.EditWindow),
+		Notification: notifications,
 	}
 }
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/morf1lo/blog-app/internal/models"
+	"github.com/morf1lo/blog-app/internal/repository"
+)
+
+var tagRegexp = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,31}$`)
+
+type TagService struct {
+	tags      repository.Tag
+	posts     repository.Post
+	annotator *PostAnnotator
+}
+
+func NewTagService(tags repository.Tag, posts repository.Post, annotator *PostAnnotator) *TagService {
+	return &TagService{tags: tags, posts: posts, annotator: annotator}
+}
+
+func (s *TagService) FindPopularTags(limit int) ([]models.TagCount, error) {
+	return s.tags.FindPopular(limit)
+}
+
+func (s *TagService) FindTagPosts(tag string, viewerID int64, pagination models.Pagination) (*models.Page[models.Post], error) {
+	tag, err := normalizeTag(tag)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.annotator.page(viewerID)(s.posts.FindByTag(tag, pagination))
+}
+
+// normalizeTag lowercases a tag and strips a leading '#', so "#Go" and "go" are the same tag.
+func normalizeTag(tag string) (string, error) {
+	tag = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(tag), "#"))
+	if !tagRegexp.MatchString(tag) {
+		return "", errInvalidTag
+	}
+	return tag, nil
+}
+
+func normalizeTags(tags []string) ([]string, error) {
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag, err := normalizeTag(tag)
+		if err != nil {
+			return nil, err
+		}
+		if seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized, nil
+}
@@ -0,0 +1,260 @@
+package service
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/base32"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/morf1lo/blog-app/internal/models"
+	"github.com/morf1lo/blog-app/internal/repository"
+	"github.com/morf1lo/blog-app/internal/utils/auth"
+	"github.com/morf1lo/blog-app/internal/utils/totp"
+)
+
+const (
+	twoFactorIssuer   = "Blog App"
+	recoveryCodeCount = 10
+	// Codes from one step before or after the current one are accepted to tolerate clock drift
+	twoFactorSkew              = 1
+	twoFactorChallengeTTL      = 5 * time.Minute
+	maxTwoFactorChallengeTries = 5
+	// Every sign-in with the password starts a new challenge, so wrong codes
+	// are also limited per user to stop guessing across challenges
+	maxTwoFactorFailures = 10
+	twoFactorLockout     = 15 * time.Minute
+)
+
+type TwoFactorService struct {
+	twoFactor repository.TwoFactor
+	users     repository.User
+}
+
+func NewTwoFactorService(twoFactor repository.TwoFactor, users repository.User) *TwoFactorService {
+	return &TwoFactorService{
+		twoFactor: twoFactor,
+		users: users,
+	}
+}
+
+func (s *TwoFactorService) FindTwoFactorStatus(userID int64) (*models.TwoFactorStatus, error) {
+	enabled, err := s.IsTwoFactorEnabled(userID)
+	if err != nil || !enabled {
+		return &models.TwoFactorStatus{}, err
+	}
+
+	recoveryCodesLeft, err := s.twoFactor.CountRecoveryCodes(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.TwoFactorStatus{Enabled: true, RecoveryCodesLeft: recoveryCodesLeft}, nil
+}
+
+func (s *TwoFactorService) IsTwoFactorEnabled(userID int64) (bool, error) {
+	twoFactor, err := s.twoFactor.Find(userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+	return twoFactor.EnabledAt != nil, nil
+}
+
+// EnrollTwoFactor generates a new secret and recovery codes. They take effect
+// once ConfirmTwoFactor is called with a code from the authenticator app.
+func (s *TwoFactorService) EnrollTwoFactor(userID int64) (*models.TwoFactorEnrollment, error) {
+	user, err := s.users.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	recoveryCodes := make([]string, recoveryCodeCount)
+	recoveryCodeHashes := make([]string, recoveryCodeCount)
+	for i := range recoveryCodes {
+		recoveryCodes[i], err = generateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		recoveryCodeHashes[i] = hashRecoveryCode(recoveryCodes[i])
+	}
+
+	enrolled, err := s.twoFactor.Enroll(models.TwoFactor{UserID: userID, Secret: secret, CreatedAt: now()}, recoveryCodeHashes)
+	if err != nil {
+		return nil, err
+	}
+	if !enrolled {
+		return nil, errTwoFactorEnabled
+	}
+
+	return &models.TwoFactorEnrollment{
+		Secret: secret,
+		URI: totp.URI(twoFactorIssuer, user.Username, secret),
+		RecoveryCodes: recoveryCodes,
+	}, nil
+}
+
+func (s *TwoFactorService) ConfirmTwoFactor(userID int64, code string) error {
+	twoFactor, err := s.twoFactor.Find(userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return errTwoFactorNotEnrolled
+		}
+		return err
+	}
+	if twoFactor.EnabledAt != nil {
+		return errTwoFactorEnabled
+	}
+
+	confirmedAt := now()
+	step, ok := totp.Validate(twoFactor.Secret, strings.TrimSpace(code), confirmedAt, twoFactorSkew)
+	if !ok {
+		return errInvalidTwoFactorCode
+	}
+
+	enabled, err := s.twoFactor.Enable(userID, step, confirmedAt)
+	if err != nil {
+		return err
+	}
+	if !enabled {
+		return errTwoFactorEnabled
+	}
+
+	return nil
+}
+
+func (s *TwoFactorService) DisableTwoFactor(userID int64, password string) error {
+	hash, err := s.users.FindPassword(userID)
+	if err != nil {
+		return err
+	}
+
+	if matchPassword := auth.VerifyPassword([]byte(hash), []byte(password)); !matchPassword {
+		return errInvalidPassword
+	}
+
+	if _, err := s.twoFactor.Find(userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return errTwoFactorNotEnrolled
+		}
+		return err
+	}
+
+	return s.twoFactor.Disable(userID)
+}
+
+// CreateTwoFactorChallenge starts the second step of a sign-in for a user
+// whose password has been verified.
+func (s *TwoFactorService) CreateTwoFactorChallenge(userID int64) (string, time.Time, error) {
+	challenge, err := auth.GenerateRefreshToken()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	createdAt := now()
+	expiresAt := createdAt.Add(twoFactorChallengeTTL)
+	if err := s.twoFactor.CreateChallenge(userID, auth.HashToken(challenge), createdAt, expiresAt); err != nil {
+		return "", time.Time{}, err
+	}
+
+	return challenge, expiresAt, nil
+}
+
+// VerifyTwoFactorChallenge completes a sign-in with an authenticator code or
+// a recovery code and returns the user to create a session for. A challenge
+// works once and is dropped after too many wrong codes. Too many wrong codes
+// for a user, over any number of challenges, lock their sign-in for a while.
+func (s *TwoFactorService) VerifyTwoFactorChallenge(token string, code string) (int64, error) {
+	challenge, err := s.twoFactor.FindChallenge(auth.HashToken(token))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, errInvalidChallenge
+		}
+		return 0, err
+	}
+
+	verifiedAt := now()
+	if !verifiedAt.Before(challenge.ExpiresAt) {
+		return 0, errInvalidChallenge
+	}
+
+	twoFactor, err := s.twoFactor.Find(challenge.UserID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, errInvalidChallenge
+		}
+		return 0, err
+	}
+
+	if twoFactor.LockedUntil != nil && verifiedAt.Before(*twoFactor.LockedUntil) {
+		return 0, errTwoFactorLocked
+	}
+
+	valid, err := s.verifyCode(twoFactor, code, verifiedAt)
+	if err != nil {
+		return 0, err
+	}
+	if !valid {
+		if err := s.twoFactor.RecordFailedAttempt(challenge.ID, maxTwoFactorChallengeTries); err != nil {
+			return 0, err
+		}
+		if err := s.twoFactor.RecordFailedCode(challenge.UserID, maxTwoFactorFailures, verifiedAt.Add(twoFactorLockout)); err != nil {
+			return 0, err
+		}
+		return 0, errInvalidTwoFactorCode
+	}
+
+	if twoFactor.FailedAttempts > 0 {
+		if err := s.twoFactor.ResetFailedCodes(challenge.UserID); err != nil {
+			return 0, err
+		}
+	}
+
+	deleted, err := s.twoFactor.DeleteChallenge(challenge.ID)
+	if err != nil {
+		return 0, err
+	}
+	if !deleted {
+		return 0, errInvalidChallenge
+	}
+
+	return challenge.UserID, nil
+}
+
+func (s *TwoFactorService) verifyCode(twoFactor *models.TwoFactor, code string, at time.Time) (bool, error) {
+	code = strings.TrimSpace(code)
+	if len(code) == totp.Digits {
+		step, ok := totp.Validate(twoFactor.Secret, code, at, twoFactorSkew)
+		if !ok {
+			return false, nil
+		}
+		return s.twoFactor.UseStep(twoFactor.UserID, step)
+	}
+
+	return s.twoFactor.UseRecoveryCode(twoFactor.UserID, hashRecoveryCode(code), at)
+}
+
+// generateRecoveryCode returns a code like "k3m9q-x2b7d".
+func generateRecoveryCode() (string, error) {
+	codeBytes := make([]byte, 7)
+	if _, err := rand.Read(codeBytes); err != nil {
+		return "", err
+	}
+
+	code := strings.ToLower(base32.StdEncoding.EncodeToString(codeBytes))[:10]
+	return code[:5] + "-" + code[5:], nil
+}
+
+// hashRecoveryCode ignores case and separators so that codes can be typed loosely.
+func hashRecoveryCode(code string) string {
+	code = strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	return auth.HashToken(code)
+}
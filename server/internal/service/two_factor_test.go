@@ -0,0 +1,199 @@
+package service
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/morf1lo/blog-app/internal/config"
+	"github.com/morf1lo/blog-app/internal/db"
+	"github.com/morf1lo/blog-app/internal/migrate"
+	"github.com/morf1lo/blog-app/internal/models"
+	"github.com/morf1lo/blog-app/internal/repository"
+	"github.com/morf1lo/blog-app/internal/utils/totp"
+)
+
+// newTestTwoFactorService returns a service on a migrated SQLite database and
+// a user who has confirmed two-factor authentication, with their enrollment.
+func newTestTwoFactorService(t *testing.T) (*TwoFactorService, int64, *models.TwoFactorEnrollment) {
+	t.Helper()
+
+	conn, err := db.Connect(config.DBConfig{Driver: db.DriverSQLite, Path: filepath.Join(t.TempDir(), "test.db")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	migrator, err := migrate.NewMigrator(conn, db.DriverSQLite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrator.Up(); err != nil {
+		t.Fatal(err)
+	}
+
+	repos := repository.NewRepository(conn)
+	userID, err := repos.User.Create(models.User{Username: "alice", Email: "alice@example.com", Password: "hash"}, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewTwoFactorService(repos.TwoFactor, repos.User)
+	enrollment, err := s.EnrollTwoFactor(userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.ConfirmTwoFactor(userID, stepCode(t, enrollment.Secret, 0)); err != nil {
+		t.Fatal(err)
+	}
+
+	return s, userID, enrollment
+}
+
+// stepCode returns the code of the time step offset steps from the current one.
+func stepCode(t *testing.T, secret string, offset int64) string {
+	t.Helper()
+
+	code, err := totp.Code(secret, totp.Step(now())+offset)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return code
+}
+
+// wrongCode returns a code that no step within the accepted skew produces.
+func wrongCode(t *testing.T, secret string) string {
+	t.Helper()
+
+	valid := make(map[string]bool)
+	for offset := int64(-twoFactorSkew - 1); offset <= twoFactorSkew+1; offset++ {
+		valid[stepCode(t, secret, offset)] = true
+	}
+	for _, code := range []string{"000000", "111111", "222222", "333333", "444444", "555555"} {
+		if !valid[code] {
+			return code
+		}
+	}
+	t.Fatal("no wrong code found")
+	return ""
+}
+
+func newChallenge(t *testing.T, s *TwoFactorService, userID int64) string {
+	t.Helper()
+
+	challenge, _, err := s.CreateTwoFactorChallenge(userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return challenge
+}
+
+func TestTwoFactorCodeCannotBeReplayed(t *testing.T) {
+	s, userID, enrollment := newTestTwoFactorService(t)
+
+	// The code that confirmed the enrollment has been used
+	challenge := newChallenge(t, s, userID)
+	if _, err := s.VerifyTwoFactorChallenge(challenge, stepCode(t, enrollment.Secret, 0)); !errors.Is(err, errInvalidTwoFactorCode) {
+		t.Fatalf("replayed confirmation code: got %v, want %v", err, errInvalidTwoFactorCode)
+	}
+
+	// The next step is within the skew and unused
+	next := stepCode(t, enrollment.Secret, twoFactorSkew)
+	verifiedID, err := s.VerifyTwoFactorChallenge(challenge, next)
+	if err != nil || verifiedID != userID {
+		t.Fatalf("VerifyTwoFactorChallenge = %d, %v, want %d", verifiedID, err, userID)
+	}
+
+	if _, err := s.VerifyTwoFactorChallenge(challenge, next); !errors.Is(err, errInvalidChallenge) {
+		t.Errorf("reusing a challenge: got %v, want %v", err, errInvalidChallenge)
+	}
+	if _, err := s.VerifyTwoFactorChallenge(newChallenge(t, s, userID), next); !errors.Is(err, errInvalidTwoFactorCode) {
+		t.Errorf("replayed code on a new challenge: got %v, want %v", err, errInvalidTwoFactorCode)
+	}
+}
+
+func TestRecoveryCodeWorksOnce(t *testing.T) {
+	s, userID, enrollment := newTestTwoFactorService(t)
+
+	// Recovery codes may be typed without the dash and in upper case
+	code := strings.ToUpper(strings.Replace(enrollment.RecoveryCodes[0], "-", "", 1))
+	if _, err := s.VerifyTwoFactorChallenge(newChallenge(t, s, userID), code); err != nil {
+		t.Fatalf("first use of a recovery code: %v", err)
+	}
+	if _, err := s.VerifyTwoFactorChallenge(newChallenge(t, s, userID), enrollment.RecoveryCodes[0]); !errors.Is(err, errInvalidTwoFactorCode) {
+		t.Errorf("second use of a recovery code: got %v, want %v", err, errInvalidTwoFactorCode)
+	}
+
+	status, err := s.FindTwoFactorStatus(userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.RecoveryCodesLeft != recoveryCodeCount-1 {
+		t.Errorf("recovery codes left = %d, want %d", status.RecoveryCodesLeft, recoveryCodeCount-1)
+	}
+}
+
+func TestChallengeIsDroppedAfterTooManyWrongCodes(t *testing.T) {
+	s, userID, enrollment := newTestTwoFactorService(t)
+
+	challenge := newChallenge(t, s, userID)
+	wrong := wrongCode(t, enrollment.Secret)
+	for i := 0; i < maxTwoFactorChallengeTries; i++ {
+		if _, err := s.VerifyTwoFactorChallenge(challenge, wrong); !errors.Is(err, errInvalidTwoFactorCode) {
+			t.Fatalf("wrong code %d: got %v, want %v", i+1, err, errInvalidTwoFactorCode)
+		}
+	}
+
+	if _, err := s.VerifyTwoFactorChallenge(challenge, stepCode(t, enrollment.Secret, twoFactorSkew)); !errors.Is(err, errInvalidChallenge) {
+		t.Errorf("valid code after too many wrong ones: got %v, want %v", err, errInvalidChallenge)
+	}
+}
+
+func TestWrongCodesAcrossChallengesLockSignIn(t *testing.T) {
+	s, userID, enrollment := newTestTwoFactorService(t)
+	wrong := wrongCode(t, enrollment.Secret)
+
+	// A fresh challenge per wrong code, as an attacker who knows the password would do
+	guess := func(times int) {
+		t.Helper()
+		for i := 0; i < times; i++ {
+			if _, err := s.VerifyTwoFactorChallenge(newChallenge(t, s, userID), wrong); !errors.Is(err, errInvalidTwoFactorCode) {
+				t.Fatalf("wrong code %d: got %v, want %v", i+1, err, errInvalidTwoFactorCode)
+			}
+		}
+	}
+
+	// A successful sign-in starts the count over
+	guess(maxTwoFactorFailures - 1)
+	if _, err := s.VerifyTwoFactorChallenge(newChallenge(t, s, userID), enrollment.RecoveryCodes[0]); err != nil {
+		t.Fatalf("sign-in just below the limit: %v", err)
+	}
+
+	guess(maxTwoFactorFailures)
+	if _, err := s.VerifyTwoFactorChallenge(newChallenge(t, s, userID), stepCode(t, enrollment.Secret, twoFactorSkew)); !errors.Is(err, errTwoFactorLocked) {
+		t.Fatalf("valid code while locked: got %v, want %v", err, errTwoFactorLocked)
+	}
+	if _, err := s.VerifyTwoFactorChallenge(newChallenge(t, s, userID), enrollment.RecoveryCodes[1]); !errors.Is(err, errTwoFactorLocked) {
+		t.Errorf("recovery code while locked: got %v, want %v", err, errTwoFactorLocked)
+	}
+
+	// Codes sent while locked out are not spent
+	status, err := s.FindTwoFactorStatus(userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.RecoveryCodesLeft != recoveryCodeCount-1 {
+		t.Errorf("recovery codes left = %d, want %d", status.RecoveryCodesLeft, recoveryCodeCount-1)
+	}
+
+	lockedUntil := now().Add(twoFactorLockout)
+	twoFactor, err := s.twoFactor.Find(userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if twoFactor.LockedUntil == nil || twoFactor.LockedUntil.Before(lockedUntil.Add(-time.Minute)) {
+		t.Errorf("locked until %v, want about %v", twoFactor.LockedUntil, lockedUntil)
+	}
+}
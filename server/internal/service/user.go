@@ -1,7 +1,6 @@
 package service
 
 import (
-	"database/sql"
 	"mime/multipart"
 	"os"
 	"path/filepath"
@@ -9,20 +8,24 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/morf1lo/blog-app/internal/models"
+	"github.com/morf1lo/blog-app/internal/repository"
 	"github.com/morf1lo/blog-app/internal/utils/auth"
 )
 
 type UserService struct {
-	db *sql.DB
+	users   repository.User
+	follows repository.Follow
+	notifications *NotificationService
+
+	serverURL string
 }
 
-func NewUserService(db *sql.DB) *UserService {
-	return &UserService{db: db}
+func NewUserService(users repository.User, follows repository.Follow, notifications *NotificationService, serverURL string) *UserService {
+	return &UserService{users: users, follows: follows, notifications: notifications, serverURL: serverURL}
 }
 
 func (s *UserService) DeleteUser(userID int64, confirmPassword string) error {
-	var password string
-	err := s.db.QueryRow("SELECT password FROM users WHERE id = ?", userID).Scan(&password)
+	password, err := s.users.FindPassword(userID)
 	if err != nil {
 		return err
 	}
@@ -31,14 +34,14 @@ func (s *UserService) DeleteUser(userID int64, confirmPassword string) error {
 		return errInvalidPassword
 	}
 
-	if err := deleteUserData(s.db, userID); err != nil {
+	if err := s.deleteUserData(userID); err != nil {
 		return errInternalServer
 	}
 
 	return nil
 }
 
-func deleteUserData(db *sql.DB, userID int64) error {
+func (s *UserService) deleteUserData(userID int64) error {
 	// Delete user profile picture
 	path := "public/avatars"
 
@@ -56,50 +59,15 @@ func deleteUserData(db *sql.DB, userID int64) error {
 	}
 
 	// Delete user data from Database
-	tx, err := db.Begin()
-	if err != nil {
-		return err
-	}
-	defer tx.Rollback()
-
-	queries := [4]string{
-		"DELETE FROM users WHERE id = ?",
-		"DELETE FROM posts WHERE author_id = ?",
-		"DELETE FROM comments WHERE author_id = ?",
-		"DELETE FROM likes WHERE user_id = ?",
-	}
-
-	for _, query := range queries {
-		_, err := tx.Exec(query, userID)
-		if err != nil {
-			return err
-		}
-	}
-
-	_, err = tx.Exec("DELETE FROM followers WHERE user_id = ? OR following_id = ?", userID, userID)
-	if err != nil {
-		return err
-	}
-
-	return tx.Commit()
+	return s.users.Delete(userID)
 }
 
 func (s *UserService) FindUserById(userID int64) (*models.User, error) {
-	var user models.User
-	err := s.db.QueryRow("SELECT id, username, email, avatar, created_at FROM users WHERE id = ?", userID).Scan(&user.ID, &user.Username, &user.Email, &user.Avatar, &user.CreatedAt)
-	if err != nil {
-		return nil, err
-	}
-	return &user, nil
+	return s.users.FindByID(userID)
 }
 
 func (s *UserService) FindUserByUsername(username string) (*models.User, error) {
-	var user models.User
-	err := s.db.QueryRow("SELECT id, username, email, avatar, created_at FROM users WHERE username = ?", username).Scan(&user.ID, &user.Username, &user.Email, &user.Avatar, &user.CreatedAt)
-	if err != nil {
-		return nil, err
-	}
-	return &user, nil
+	return s.users.FindByUsername(username)
 }
 
 func (s *UserService) SetAvatar(c *gin.Context, file *multipart.FileHeader, userID int64) error {
@@ -126,100 +94,71 @@ func (s *UserService) SetAvatar(c *gin.Context, file *multipart.FileHeader, user
 		return err
 	}
 
-	avatar := os.Getenv("SERVER_URL") + "/public/avatars/" + fileName
-	_, err = s.db.Exec("UPDATE users SET avatar = ? WHERE id = ?", avatar, userID)
-	if err != nil {
-		return err
-	}
-	return nil
+	avatar := s.serverURL + "/public/avatars/" + fileName
+	return s.users.SetAvatar(userID, avatar)
 }
 
-func (s *UserService) Follow(userID int64, followingID int64) error {
-	// Checking user existence
-	var exists bool
-	err := s.db.QueryRow("SELECT EXISTS(SELECT 1 FROM users WHERE id = ?)", followingID).Scan(&exists)
-	if err != nil {
-		return err
-	}
-	if !exists {
-		return errUserNotFound
+// Follow toggles following a user and returns whether the user is followed afterwards.
+func (s *UserService) Follow(userID int64, followingID int64) (bool, error) {
+	if err := s.checkFollowable(userID, followingID); err != nil {
+		return false, err
 	}
 
-	var alreadyFollowed bool
-	err = s.db.QueryRow("SELECT EXISTS(SELECT 1 FROM followers WHERE user_id = ? AND following_id = ?)", userID, followingID).Scan(&alreadyFollowed)
+	followed, err := s.follows.Toggle(userID, followingID)
 	if err != nil {
-		return err
+		return false, err
 	}
 
-	if alreadyFollowed {
-		_, err = s.db.Exec("DELETE FROM followers WHERE user_id = ? AND following_id = ?", userID, followingID)
-		if err != nil {
-			return err
-		}
-	} else {
-		_, err = s.db.Exec("INSERT INTO followers(user_id, following_id) VALUES(?, ?)", userID, followingID)
-		if err != nil {
-			return err
-		}
+	if followed {
+		s.notifications.notify(followingID, models.NotificationFollow, userID, nil, nil)
 	}
 
-	return nil
+	return followed, nil
 }
 
-func (s *UserService) FindUserFollowers(userID int64) (*[]models.User, error) {
-	var followerIDs []int64
-	rows, err := s.db.Query("SELECT user_id FROM followers WHERE following_id = ?", userID)
-	if err != nil {
-		return nil, err
+func (s *UserService) AddFollow(userID int64, followingID int64) error {
+	if err := s.checkFollowable(userID, followingID); err != nil {
+		return err
 	}
-	defer rows.Close()
 
-	for rows.Next() {
-		var followerID int64
-		if err := rows.Scan(&followerID); err != nil {
-			return nil, err
-		}
-		followerIDs = append(followerIDs, followerID)
+	added, err := s.follows.Add(userID, followingID)
+	if err != nil {
+		return err
 	}
 
-	var followers []models.User
-	for _, followingID := range followerIDs {
-		var follower models.User
-		err := s.db.QueryRow("SELECT id, username, avatar FROM users WHERE id = ?", followingID).Scan(&follower.ID, &follower.Username, &follower.Avatar)
-		if err != nil {
-			return nil, err
-		}
-		followers = append(followers, follower)
+	if added {
+		s.notifications.notify(followingID, models.NotificationFollow, userID, nil, nil)
 	}
 
-	return &followers, nil
+	return nil
 }
 
-func (s *UserService) FindUserFollows(userID int64) (*[]models.User, error) {
-	var followingIDs []int64
-	rows, err := s.db.Query("SELECT following_id FROM followers WHERE user_id = ?", userID)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
+func (s *UserService) RemoveFollow(userID int64, followingID int64) error {
+	_, err := s.follows.Remove(userID, followingID)
+	return err
+}
 
-	for rows.Next() {
-		var followingID int64
-		if err := rows.Scan(&followingID); err != nil {
-			return nil, err
-		}
-		followingIDs = append(followingIDs, followingID)
+func (s *UserService) checkFollowable(userID int64, followingID int64) error {
+	if userID == followingID {
+		return errFollowSelf
 	}
 
-	var follows []models.User
-	for _, followingID := range followingIDs {
-		var follow models.User
-		err := s.db.QueryRow("SELECT id, username, avatar FROM users WHERE id = ?", followingID).Scan(&follow.ID, &follow.Username, &follow.Avatar)
-		if err != nil {
-			return nil, err
-		}
-		follows = append(follows, follow)
+	// Checking user existence
+	exists, err := s.users.Exists(followingID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return errUserNotFound
 	}
 
-	return &follows, nil
+	return nil
+}
+
+func (s *UserService) FindUserFollowers(userID int64, pagination models.Pagination) (*models.Page[models.User], error) {
+	return s.follows.FindFollowers(userID, pagination)
+}
+
+func (s *UserService) FindUserFollows(userID int64, pagination models.Pagination) (*models.Page[models.User], error) {
+	return s.follows.FindFollows(userID, pagination)
 }
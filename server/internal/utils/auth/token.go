@@ -2,37 +2,136 @@ package auth
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/base64"
-	"os"
+	"encoding/hex"
+	"errors"
+	"net/http"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/morf1lo/blog-app/internal/models"
+)
+
+const (
+	AccessTokenCookie  = "jwt"
+	RefreshTokenCookie = "refresh_token"
+
+	// The refresh token is only sent to the endpoints that use it
+	refreshTokenPath = "/api/auth"
+
+	// PersonalAccessTokenPrefix tells personal access tokens apart from session access tokens
+	PersonalAccessTokenPrefix = "blog_pat_"
+	personalAccessTokenShown  = len(PersonalAccessTokenPrefix) + 6
 )
 
-func generateToken(id int64) (string, error) {
+var ErrInvalidToken = errors.New("authentication token is not valid")
+
+// GenerateAccessToken signs a short-lived token for one session of a user.
+func GenerateAccessToken(userID int64, sessionID int64, secret string, expiresAt time.Time) (string, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"uid": id,
-		"exp": time.Now().Add(time.Hour * 24 * 7).Unix(),
+		"uid": userID,
+		"sid": sessionID,
+		"exp": expiresAt.Unix(),
 	})
 
-	jwt, err := token.SignedString([]byte(os.Getenv("SECRET")))
+	return token.SignedString([]byte(secret))
+}
+
+// ParseAccessToken verifies an access token and returns its user and session.
+// Expired tokens are reported with jwt.ErrTokenExpired.
+func ParseAccessToken(token string, secret string) (int64, int64, error) {
+	parsedToken, err := jwt.Parse(token, func(token *jwt.Token) (interface{}, error) {
+		return []byte(secret), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
 	if err != nil {
+		return 0, 0, err
+	}
+
+	claims, ok := parsedToken.Claims.(jwt.MapClaims)
+	if !ok || !parsedToken.Valid {
+		return 0, 0, ErrInvalidToken
+	}
+
+	// Tokens issued before sessions existed have no session and must sign in again
+	userID, okUser := claims["uid"].(float64)
+	sessionID, okSession := claims["sid"].(float64)
+	if !okUser || !okSession {
+		return 0, 0, ErrInvalidToken
+	}
+
+	return int64(userID), int64(sessionID), nil
+}
+
+// GenerateRefreshToken returns a random opaque token. Only its hash is stored.
+func GenerateRefreshToken() (string, error) {
+	tokenBytes := make([]byte, 32)
+	if _, err := rand.Read(tokenBytes); err != nil {
 		return "", err
 	}
+	return base64.RawURLEncoding.EncodeToString(tokenBytes), nil
+}
+
+// GeneratePersonalAccessToken returns a new personal access token and the
+// beginning of it that is kept to help users recognise the token.
+func GeneratePersonalAccessToken() (string, string, error) {
+	secret, err := GenerateRefreshToken()
+	if err != nil {
+		return "", "", err
+	}
+
+	token := PersonalAccessTokenPrefix + secret
+	return token, token[:personalAccessTokenShown], nil
+}
+
+func IsPersonalAccessToken(token string) bool {
+	return strings.HasPrefix(token, PersonalAccessTokenPrefix)
+}
 
-	return jwt, nil
+func HashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
 }
 
-func CreateSendToken(c *gin.Context, userID int64) error {
-	jwt, err := generateToken(userID)
+// AccessTokenFromRequest reads the access token from a bearer Authorization
+// header, falling back to the access token cookie.
+func AccessTokenFromRequest(c *gin.Context) (string, bool) {
+	scheme, token, found := strings.Cut(c.GetHeader("Authorization"), " ")
+	if found && strings.EqualFold(scheme, "Bearer") {
+		token = strings.TrimSpace(token)
+		return token, token != ""
+	}
+
+	token, err := c.Cookie(AccessTokenCookie)
 	if err != nil {
-		return err
+		return "", false
 	}
+	return token, true
+}
+
+// Cookies describes how the token cookies are set.
+type Cookies struct {
+	Domain   string
+	SameSite http.SameSite
+	Secure   bool
+}
+
+// SendTokens sets the token cookies. The access token cookie outlives the
+// token itself so that an expired token is reported as such instead of missing.
+func (cookies Cookies) SendTokens(c *gin.Context, tokens *models.SessionTokens) {
+	maxAge := int(time.Until(tokens.RefreshExpiresAt).Seconds())
+	c.SetSameSite(cookies.SameSite)
+	c.SetCookie(AccessTokenCookie, tokens.AccessToken, maxAge, "/", cookies.Domain, cookies.Secure, true)
+	c.SetCookie(RefreshTokenCookie, tokens.RefreshToken, maxAge, refreshTokenPath, cookies.Domain, cookies.Secure, true)
+}
 
-	c.SetCookie("jwt", jwt, int(time.Now().Add(time.Hour * 24).Unix()), "/", "localhost", true, true)
-	return nil
+func (cookies Cookies) ClearTokens(c *gin.Context) {
+	c.SetSameSite(cookies.SameSite)
+	c.SetCookie(AccessTokenCookie, "", -1, "/", cookies.Domain, cookies.Secure, true)
+	c.SetCookie(RefreshTokenCookie, "", -1, refreshTokenPath, cookies.Domain, cookies.Secure, true)
 }
 
 func GenerateResetToken() (string, error) {
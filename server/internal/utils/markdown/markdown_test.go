@@ -0,0 +1,75 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+)
+
+func render(t *testing.T, source string) string {
+	t.Helper()
+
+	html, err := Render(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return html
+}
+
+func TestRenderStripsUnsafeHTML(t *testing.T) {
+	cases := []struct {
+		name   string
+		source string
+		// none of these may appear in the output, compared case-insensitively
+		forbidden []string
+	}{
+		{"script block", "<script>alert(1)</script>", []string{"<script", "alert(1)"}},
+		{"inline script", "text <script>alert(1)</script> text", []string{"<script"}},
+		{"event handler", `<img src="x.png" onerror="alert(1)">`, []string{"onerror", "<img"}},
+		{"event handler in a block", `<div onclick="alert(1)">hi</div>`, []string{"onclick", "<div"}},
+		{"raw html block", "<iframe src=\"https://example.com\"></iframe>\n\n<style>body{}</style>", []string{"<iframe", "<style"}},
+		{"inline html", "a <b onmouseover=\"alert(1)\">b</b>", []string{"onmouseover", "<b "}},
+		{"javascript link", "[click](javascript:alert(1))", []string{"javascript:"}},
+		{"javascript link with mixed case", "[click](JaVaScRiPt:alert(1))", []string{"javascript:"}},
+		{"javascript autolink", "<javascript:alert(1)>", []string{"href=\"javascript:"}},
+		{"data link", "[click](data:text/html;base64,PHNjcmlwdD5hbGVydCgxKTwvc2NyaXB0Pg==)", []string{"data:"}},
+		{"data image", "![x](data:image/svg+xml;base64,PHN2Zz48L3N2Zz4=)", []string{"data:"}},
+		{"javascript in reference link", "[click][x]\n\n[x]: javascript:alert(1)", []string{"javascript:"}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			html := strings.ToLower(render(t, tc.source))
+			for _, forbidden := range tc.forbidden {
+				if strings.Contains(html, forbidden) {
+					t.Errorf("output contains %q: %s", forbidden, html)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderKeepsSafeMarkdown(t *testing.T) {
+	cases := []struct {
+		name     string
+		source   string
+		expected []string
+	}{
+		{"emphasis", "*a* **b**", []string{"<em>a</em>", "<strong>b</strong>"}},
+		{"strikethrough", "~~gone~~", []string{"<del>gone</del>"}},
+		{"table", "| a | b |\n|---|---|\n| 1 | 2 |", []string{"<table>", "<th>a</th>", "<td>2</td>"}},
+		{"link", "[site](https://example.com)", []string{`href="https://example.com"`, `rel="nofollow"`}},
+		{"code language", "```go\nfmt.Println()\n```", []string{`<code class="language-go">`}},
+		{"escaped text", "1 < 2 & 3", []string{"1 &lt; 2 &amp; 3"}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			html := render(t, tc.source)
+			for _, expected := range tc.expected {
+				if !strings.Contains(html, expected) {
+					t.Errorf("output lacks %q: %s", expected, html)
+				}
+			}
+		})
+	}
+}
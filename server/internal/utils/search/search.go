@@ -0,0 +1,217 @@
+package search
+
+import (
+	"html"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MaxTermLength is the longest term stored in the index, in bytes.
+const MaxTermLength = 64
+
+// MinPrefixLength keeps prefix queries such as "ab*" from matching most of the index.
+const MinPrefixLength = 3
+
+// MaxClauses and MaxPhraseTerms bound the work a single query can ask for.
+// Clauses past the limit are ignored and longer phrases are cut short.
+const (
+	MaxClauses     = 8
+	MaxPhraseTerms = 6
+)
+
+// Words too common to narrow down a search. They are still indexed, so they
+// count inside phrases, but a query never matches on them alone.
+var stopwords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true,
+	"be": true, "but": true, "by": true, "for": true, "if": true, "in": true,
+	"into": true, "is": true, "it": true, "no": true, "not": true, "of": true,
+	"on": true, "or": true, "such": true, "that": true, "the": true, "their": true,
+	"then": true, "there": true, "these": true, "they": true, "this": true,
+	"to": true, "was": true, "will": true, "with": true,
+}
+
+func IsStopword(term string) bool {
+	return stopwords[term]
+}
+
+type Token struct {
+	Term  string
+	Start int
+	End   int
+}
+
+// Tokenize splits text into lowercase terms made of letters and digits,
+// keeping the byte offsets of each term in the original text.
+func Tokenize(text string) []Token {
+	var tokens []Token
+
+	start := -1
+	for i, r := range text {
+		isWordRune := unicode.IsLetter(r) || unicode.IsDigit(r)
+		if isWordRune && start < 0 {
+			start = i
+		}
+		if !isWordRune && start >= 0 {
+			tokens = append(tokens, newToken(text, start, i))
+			start = -1
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, newToken(text, start, len(text)))
+	}
+
+	return tokens
+}
+
+func newToken(text string, start int, end int) Token {
+	return Token{Term: normalizeTerm(text[start:end]), Start: start, End: end}
+}
+
+func normalizeTerm(term string) string {
+	term = strings.ToLower(term)
+	if len(term) <= MaxTermLength {
+		return term
+	}
+
+	cut := MaxTermLength
+	for cut > 0 && !utf8.RuneStart(term[cut]) {
+		cut--
+	}
+	return term[:cut]
+}
+
+// Clause is one unit of a query that a document has to match: a single term,
+// a term prefix (word*) or a phrase ("several words" in quotes).
+type Clause struct {
+	Terms  []string
+	Prefix bool
+}
+
+func (c Clause) IsPhrase() bool {
+	return len(c.Terms) > 1
+}
+
+// Matches reports whether an indexed term satisfies a single-term clause.
+func (c Clause) Matches(term string) bool {
+	if c.Prefix {
+		return strings.HasPrefix(term, c.Terms[0])
+	}
+	return term == c.Terms[0]
+}
+
+// ParseQuery splits a user query into clauses. Words are matched exactly,
+// words ending in '*' by prefix and quoted text as a phrase. An unterminated
+// quote extends the phrase to the end of the query. Stopwords on their own and
+// phrases made only of stopwords are dropped.
+func ParseQuery(query string) []Clause {
+	var clauses []Clause
+
+	for i, part := range strings.Split(query, `"`) {
+		if i%2 == 1 {
+			var terms []string
+			meaningful := false
+			for _, token := range Tokenize(part) {
+				if len(terms) == MaxPhraseTerms {
+					break
+				}
+				terms = append(terms, token.Term)
+				meaningful = meaningful || !IsStopword(token.Term)
+			}
+			if meaningful {
+				clauses = append(clauses, Clause{Terms: terms})
+			}
+			continue
+		}
+
+		for _, word := range strings.Fields(part) {
+			prefix := strings.HasSuffix(word, "*")
+			tokens := Tokenize(word)
+			for j, token := range tokens {
+				clause := Clause{Terms: []string{token.Term}}
+				// Only the last term of "foo-bar*" is a prefix
+				if prefix && j == len(tokens)-1 && len(token.Term) >= MinPrefixLength {
+					clause.Prefix = true
+				}
+				if !clause.Prefix && IsStopword(token.Term) {
+					continue
+				}
+				clauses = append(clauses, clause)
+			}
+		}
+	}
+
+	if len(clauses) > MaxClauses {
+		clauses = clauses[:MaxClauses]
+	}
+
+	return clauses
+}
+
+// Highlight HTML-escapes text and wraps every term accepted by match in <mark>.
+// When width is positive only a window of about width bytes around the first
+// match is returned, with an ellipsis marking cut ends.
+func Highlight(text string, width int, match func(term string) bool) string {
+	tokens := Tokenize(text)
+
+	start, end := 0, len(text)
+	if width > 0 && len(text) > width {
+		start = 0
+		for _, token := range tokens {
+			if match(token.Term) {
+				start = token.Start - width/4
+				break
+			}
+		}
+		start = clampWindowStart(text, start, width)
+		end = start + width
+		if end > len(text) {
+			end = len(text)
+		}
+		for end < len(text) && !utf8.RuneStart(text[end]) {
+			end++
+		}
+	}
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+
+	offset := start
+	for _, token := range tokens {
+		if token.Start < start || token.End > end || !match(token.Term) {
+			continue
+		}
+		b.WriteString(html.EscapeString(text[offset:token.Start]))
+		b.WriteString("<mark>")
+		b.WriteString(html.EscapeString(text[token.Start:token.End]))
+		b.WriteString("</mark>")
+		offset = token.End
+	}
+	b.WriteString(html.EscapeString(text[offset:end]))
+
+	if end < len(text) {
+		b.WriteString("…")
+	}
+
+	return b.String()
+}
+
+func clampWindowStart(text string, start int, width int) int {
+	if start > len(text)-width {
+		start = len(text) - width
+	}
+	if start < 0 {
+		return 0
+	}
+
+	// Prefer starting on a word boundary
+	if space := strings.IndexAny(text[start:], " \n\t"); space >= 0 && space < width/4 {
+		return start + space + 1
+	}
+	for start > 0 && !utf8.RuneStart(text[start]) {
+		start--
+	}
+	return start
+}